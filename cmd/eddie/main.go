@@ -101,6 +101,7 @@ func main() {
 		stateStore,
 		mailService,
 		cfg.Mailserver.Receivers,
+		cfg.HTTPProxy,
 	)
 	go runner.Run(ctx)
 
@@ -139,10 +140,13 @@ func main() {
 				ConsecutiveSuccesses: specState.ConsecutiveSuccesses,
 				LastCycleStartedAt:   specState.LastCycleStartedAt,
 				LastCycleAt:          specState.LastCycleAt,
+				LastError:            specState.LastError,
+				Timings:              formatRequestTimings(specState.Timings),
 			})
 		}
 		return snapshot
 	}))
+	httpOpts = append(httpOpts, apphttp.WithHeartbeatRecorder(runner.RecordHeartbeat))
 	if cfg.HTTPServer.BasicAuthUsername != "" || cfg.HTTPServer.BasicAuthPassword != "" {
 		httpOpts = append(httpOpts, apphttp.WithBasicAuth(
 			cfg.HTTPServer.BasicAuthUsername,
@@ -188,6 +192,15 @@ func redact(value string) string {
 	return "***"
 }
 
+// formatRequestTimings joins the timings of all requests of one check run.
+func formatRequestTimings(timings []state.RequestTiming) string {
+	parts := make([]string, 0, len(timings))
+	for _, timing := range timings {
+		parts = append(parts, timing.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
 func resolveSpecBaseDir(specPath string) (string, error) {
 	expr := strings.TrimSpace(specPath)
 	if expr == "" {
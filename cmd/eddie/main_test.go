@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/state"
 )
 
 func TestResolveSpecBaseDir(t *testing.T) {
@@ -93,3 +96,14 @@ func TestResolveSpecBaseDir(t *testing.T) {
 		t.Fatalf("resolveSpecBaseDir(~unknown) error = %v, want unsupported home expansion", err)
 	}
 }
+
+func TestFormatRequestTimings(t *testing.T) {
+	got := formatRequestTimings([]state.RequestTiming{
+		{Label: "login", DNS: 2 * time.Millisecond, Connect: 5 * time.Millisecond, TLS: 20 * time.Millisecond, TTFB: 90 * time.Millisecond, Total: 95 * time.Millisecond},
+		{Label: "profile", TTFB: 40 * time.Millisecond, Total: 41 * time.Millisecond},
+	})
+	want := "login: dns 2ms, connect 5ms, tls 20ms, ttfb 90ms, total 95ms; profile: ttfb 40ms, total 41ms"
+	if got != want {
+		t.Fatalf("formatRequestTimings() = %q, want %q", got, want)
+	}
+}
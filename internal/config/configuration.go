@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -29,6 +30,8 @@ const (
 	envHTTPPort          = "EDDIE_HTTP_PORT"
 	envHTTPBasicUser     = "EDDIE_HTTP_BASIC_AUTH_USERNAME"
 	envHTTPBasicPassword = "EDDIE_HTTP_BASIC_AUTH_PASSWORD"
+	envHTTPProxy         = "EDDIE_HTTP_PROXY"
+	httpProxyNone        = "none"
 	defaultMailPort      = 587
 	envMailEndpoint      = "EDDIE_MAIL_ENDPOINT"
 	envMailPort          = "EDDIE_MAIL_PORT"
@@ -46,6 +49,7 @@ type Configuration struct {
 	StartupJitter   time.Duration
 	ShutdownTimeout time.Duration
 	LogLevel        string
+	HTTPProxy       string
 	HTTPServer      HTTPServerConfiguration
 	Mailserver      MailserverConfiguration
 }
@@ -121,6 +125,9 @@ func Load(args []string) (Configuration, error) {
 	} else if raw := os.Getenv(envLogLevelAlt); raw != "" {
 		cfg.LogLevel = raw
 	}
+	if raw := os.Getenv(envHTTPProxy); raw != "" {
+		cfg.HTTPProxy = raw
+	}
 	if raw := os.Getenv(envHTTPAddress); raw != "" {
 		cfg.HTTPServer.Address = raw
 	}
@@ -175,6 +182,7 @@ func Load(args []string) (Configuration, error) {
 	fs.DurationVar(&cfg.StartupJitter, "startup-jitter", cfg.StartupJitter, "startup jitter max delay for first-cycle checks (e.g. 10s)")
 	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "shutdown timeout (e.g. 5s)")
 	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level (DEBUG, INFO, WARN, ERROR)")
+	fs.StringVar(&cfg.HTTPProxy, "http-proxy", cfg.HTTPProxy, "proxy for http, probe and websocket checks (http://, https://, socks5:// URL or none)")
 	fs.StringVar(&cfg.HTTPServer.Address, "http-address", cfg.HTTPServer.Address, "http server listen address")
 	fs.IntVar(&cfg.HTTPServer.Port, "http-port", cfg.HTTPServer.Port, "http server listen port")
 	fs.StringVar(&cfg.HTTPServer.BasicAuthUsername, "http-basic-auth-username", cfg.HTTPServer.BasicAuthUsername, "http basic auth username")
@@ -197,10 +205,36 @@ func Load(args []string) (Configuration, error) {
 		return Configuration{}, fmt.Errorf("invalid %s: %w", envLogLevel, err)
 	}
 	cfg.LogLevel = logLevel
+	cfg.HTTPProxy = strings.TrimSpace(cfg.HTTPProxy)
+	if err := validateHTTPProxy(cfg.HTTPProxy); err != nil {
+		return Configuration{}, fmt.Errorf("invalid %s: %w", envHTTPProxy, err)
+	}
 
 	return cfg, nil
 }
 
+// validateHTTPProxy accepts an empty value, "none", or a proxy URL with an
+// http, https, socks5 or socks5h scheme and a host.
+func validateHTTPProxy(raw string) error {
+	if raw == "" || raw == httpProxyNone {
+		return nil
+	}
+	// Parse errors echo the URL, which may carry proxy credentials.
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("proxy is not a valid URL")
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("proxy has no host")
+	}
+	return nil
+}
+
 func normalizeLogLevel(raw string) (string, error) {
 	level := strings.ToUpper(strings.TrimSpace(raw))
 	switch level {
@@ -31,6 +31,7 @@ type Server struct {
 	basicAuthPassword string
 	appVersion        string
 	statusSnapshotFn  StatusSnapshotFunc
+	heartbeatFn       HeartbeatFunc
 	httpServer        *nethttp.Server
 }
 
@@ -40,6 +41,10 @@ type Option func(*Server) error
 // StatusSnapshotFunc returns the latest status information for all specs.
 type StatusSnapshotFunc func() StatusSnapshot
 
+// HeartbeatFunc records a heartbeat ping for token. signal is "success",
+// "start" or "fail". It returns false when the token is unknown.
+type HeartbeatFunc func(token, signal string) bool
+
 // StatusSnapshot is the data rendered by /.
 type StatusSnapshot struct {
 	GeneratedAt time.Time
@@ -58,6 +63,8 @@ type SpecStatus struct {
 	ConsecutiveSuccesses int
 	LastCycleStartedAt   time.Time
 	LastCycleAt          time.Time
+	LastError            string
+	Timings              string
 }
 
 type statusRow struct {
@@ -71,6 +78,8 @@ type statusRow struct {
 	ConsecutiveSuccesses int    `json:"consecutive_successes"`
 	LastCycleStartedAt   string `json:"last_cycle_started_at"`
 	LastCycleAt          string `json:"last_cycle_at"`
+	LastError            string `json:"last_error"`
+	Timings              string `json:"timings"`
 	StateClass           string `json:"state_class"`
 }
 
@@ -107,6 +116,8 @@ func New(address string, port int, opts ...Option) (*Server, error) {
 	mux.HandleFunc("/", server.statusHandler)
 	mux.HandleFunc("/healthz", server.healthzHandler)
 	mux.HandleFunc("/events", server.statusEventsHandler)
+	mux.HandleFunc("/ping/{token}", server.pingHandler)
+	mux.HandleFunc("/ping/{token}/{signal}", server.pingHandler)
 
 	server.httpServer = &nethttp.Server{
 		Addr:    net.JoinHostPort(server.address, strconv.Itoa(server.port)),
@@ -153,6 +164,17 @@ func WithStatusSnapshot(snapshotFn StatusSnapshotFunc) Option {
 	}
 }
 
+// WithHeartbeatRecorder configures the heartbeat recorder used by /ping.
+func WithHeartbeatRecorder(heartbeatFn HeartbeatFunc) Option {
+	return func(s *Server) error {
+		if heartbeatFn == nil {
+			return fmt.Errorf("heartbeat recorder function is required")
+		}
+		s.heartbeatFn = heartbeatFn
+		return nil
+	}
+}
+
 // Handler returns the configured HTTP handler.
 func (s *Server) Handler() nethttp.Handler {
 	return s.httpServer.Handler
@@ -182,6 +204,43 @@ func (s *Server) healthzHandler(w nethttp.ResponseWriter, r *nethttp.Request) {
 	})
 }
 
+// pingHandler records heartbeat check-ins. The secret token in the path
+// authenticates the caller, so basic auth is not required.
+func (s *Server) pingHandler(w nethttp.ResponseWriter, r *nethttp.Request) {
+	switch r.Method {
+	case nethttp.MethodGet, nethttp.MethodHead, nethttp.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		nethttp.Error(w, "method not allowed", nethttp.StatusMethodNotAllowed)
+		return
+	}
+	if s.heartbeatFn == nil {
+		nethttp.Error(w, "ping endpoint is not configured", nethttp.StatusServiceUnavailable)
+		return
+	}
+
+	signal := "success"
+	switch r.PathValue("signal") {
+	case "":
+	case "start":
+		signal = "start"
+	case "fail":
+		signal = "fail"
+	default:
+		nethttp.NotFound(w, r)
+		return
+	}
+
+	if !s.heartbeatFn(r.PathValue("token"), signal) {
+		nethttp.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(nethttp.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
 func (s *Server) statusHandler(w nethttp.ResponseWriter, r *nethttp.Request) {
 	if r.URL.Path != "/" {
 		nethttp.NotFound(w, r)
@@ -322,6 +381,8 @@ func buildStatusViewData(snapshot StatusSnapshot) statusViewData {
 			ConsecutiveSuccesses: specStatus.ConsecutiveSuccesses,
 			LastCycleStartedAt:   lastCycleStarted,
 			LastCycleAt:          lastCycle,
+			LastError:            specStatus.LastError,
+			Timings:              specStatus.Timings,
 			StateClass:           stateClass,
 		})
 	}
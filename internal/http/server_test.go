@@ -378,3 +378,138 @@ func TestStatusEventsRouteWithBasicAuth(t *testing.T) {
 		t.Fatalf("events body missing spec name: %q", body)
 	}
 }
+
+func TestPingRouteRecordsSignals(t *testing.T) {
+	type ping struct {
+		token  string
+		signal string
+	}
+	var got []ping
+	recorder := func(token, signal string) bool {
+		if token != "nightly-backup-token" {
+			return false
+		}
+		got = append(got, ping{token: token, signal: signal})
+		return true
+	}
+
+	server, err := New("0.0.0.0", 8080, WithBasicAuth("admin", "secret"), WithHeartbeatRecorder(recorder))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: http.MethodGet, path: "/ping/nightly-backup-token", want: http.StatusOK},
+		{method: http.MethodPost, path: "/ping/nightly-backup-token/start", want: http.StatusOK},
+		{method: http.MethodHead, path: "/ping/nightly-backup-token/fail", want: http.StatusOK},
+		{method: http.MethodGet, path: "/ping/nightly-backup-token/unknown", want: http.StatusNotFound},
+		{method: http.MethodGet, path: "/ping/wrong-token-value-1234", want: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/ping/nightly-backup-token", want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Fatalf("%s %s status = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+
+	want := []ping{
+		{token: "nightly-backup-token", signal: "success"},
+		{token: "nightly-backup-token", signal: "start"},
+		{token: "nightly-backup-token", signal: "fail"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("recorded pings = %v, want %v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Fatalf("ping[%d] = %v, want %v", idx, got[idx], want[idx])
+		}
+	}
+}
+
+func TestPingRouteWithoutRecorder(t *testing.T) {
+	server, err := New("0.0.0.0", 8080)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ping/nightly-backup-token", nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestStatusRouteShowsLastError(t *testing.T) {
+	server, err := New("0.0.0.0", 8080, WithStatusSnapshot(func() StatusSnapshot {
+		return StatusSnapshot{
+			Specs: []SpecStatus{
+				{
+					Name:      "realtime",
+					Type:      "websocket",
+					HasState:  true,
+					Status:    "failing",
+					LastError: `step 2 (expect): no message within 5s`,
+				},
+			},
+		}
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<th scope=\"col\">Last Error</th>") {
+		t.Fatalf("status body missing last error header: %q", body)
+	}
+	if !strings.Contains(body, ">step 2 (expect): no message within 5s</td>") {
+		t.Fatalf("status body missing last error: %q", body)
+	}
+}
+
+func TestStatusRouteShowsTimings(t *testing.T) {
+	server, err := New("0.0.0.0", 8080, WithStatusSnapshot(func() StatusSnapshot {
+		return StatusSnapshot{
+			Specs: []SpecStatus{
+				{
+					Name:     "api-health",
+					Type:     "http",
+					HasState: true,
+					Status:   "healthy",
+					Timings:  "dns 3ms, connect 12ms, ttfb 80ms, total 81ms",
+				},
+			},
+		}
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "<th scope=\"col\">Timings</th>") {
+		t.Fatalf("status body missing timings header: %q", body)
+	}
+	if !strings.Contains(body, ">dns 3ms, connect 12ms, ttfb 80ms, total 81ms</td>") {
+		t.Fatalf("status body missing timings: %q", body)
+	}
+}
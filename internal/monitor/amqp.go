@@ -0,0 +1,163 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func validateAMQPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	amqpSpec := parsedSpec.AMQP
+	if amqpSpec == nil {
+		return fmt.Errorf("missing amqp spec")
+	}
+
+	timeout := amqpSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	brokerURL, err := resolveSecret("amqp.url", amqpSpec.URL, amqpSpec.URLEnv, amqpSpec.URLFile)
+	if err != nil {
+		return err
+	}
+	config := amqp.Config{
+		Dial: func(network, addr string) (net.Conn, error) {
+			dialer := net.Dialer{}
+			conn, err := dialer.DialContext(checkCtx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			// The client clears this deadline once the handshake is done;
+			// closing the connection below bounds everything after that.
+			if deadline, ok := checkCtx.Deadline(); ok {
+				_ = conn.SetDeadline(deadline)
+			}
+			return conn, nil
+		},
+	}
+	if strings.HasPrefix(strings.TrimSpace(brokerURL), "amqps://") {
+		tlsConfig, err := buildClientTLSConfig(amqpSpec.TLS, amqpSpec.InsecureSkipTLS)
+		if err != nil {
+			return err
+		}
+		config.TLSClientConfig = tlsConfig
+	}
+
+	conn, err := amqp.DialConfig(strings.TrimSpace(brokerURL), config)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(checkCtx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return amqpError(checkCtx, "open channel", err)
+	}
+	defer channel.Close()
+
+	if queue := strings.TrimSpace(amqpSpec.Queue); queue != "" {
+		if err := checkAMQPQueue(checkCtx, channel, queue, amqpSpec.Expect); err != nil {
+			return err
+		}
+	}
+	if canary := amqpSpec.Canary; canary != nil {
+		if err := runAMQPCanary(checkCtx, channel, *canary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkAMQPQueue(ctx context.Context, channel *amqp.Channel, queue string, expect spec.AMQPExpect) error {
+	state, err := channel.QueueDeclarePassive(queue, false, false, false, false, nil)
+	if err != nil {
+		return amqpError(ctx, fmt.Sprintf("declare queue %q", queue), err)
+	}
+	if expect.MessagesLTE != nil && !(state.Messages <= *expect.MessagesLTE) {
+		return fmt.Errorf("queue %q has %d messages, want <= %d", queue, state.Messages, *expect.MessagesLTE)
+	}
+	if expect.MessagesGTE != nil && !(state.Messages >= *expect.MessagesGTE) {
+		return fmt.Errorf("queue %q has %d messages, want >= %d", queue, state.Messages, *expect.MessagesGTE)
+	}
+	if expect.ConsumersGTE != nil && !(state.Consumers >= *expect.ConsumersGTE) {
+		return fmt.Errorf("queue %q has %d consumers, want >= %d", queue, state.Consumers, *expect.ConsumersGTE)
+	}
+	if expect.ConsumersLTE != nil && !(state.Consumers <= *expect.ConsumersLTE) {
+		return fmt.Errorf("queue %q has %d consumers, want <= %d", queue, state.Consumers, *expect.ConsumersLTE)
+	}
+	return nil
+}
+
+// runAMQPCanary publishes a tokenized message to the canary queue through the
+// default exchange and polls the queue until the message comes back. Other
+// messages found on the queue (leftovers of earlier runs) are acknowledged and
+// dropped.
+func runAMQPCanary(ctx context.Context, channel *amqp.Channel, canary spec.AMQPCanary) error {
+	queue := strings.TrimSpace(canary.Queue)
+	if _, err := channel.QueueDeclarePassive(queue, false, false, false, false, nil); err != nil {
+		return amqpError(ctx, fmt.Sprintf("declare canary queue %q", queue), err)
+	}
+
+	token, err := newRoundTripToken()
+	if err != nil {
+		return err
+	}
+	token = "eddie-" + token
+	sentAt := time.Now()
+	err = channel.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		ContentType:  "text/plain",
+		DeliveryMode: amqp.Transient,
+		MessageId:    token,
+		Timestamp:    sentAt,
+		Body:         []byte(token),
+	})
+	if err != nil {
+		return amqpError(ctx, "publish canary", err)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		delivery, ok, err := channel.Get(queue, true)
+		if err != nil {
+			return amqpError(ctx, "get canary", err)
+		}
+		if ok && string(delivery.Body) == token {
+			latency := time.Since(sentAt)
+			if canary.MaxLatency > 0 && latency > canary.MaxLatency {
+				return fmt.Errorf("canary round trip took %s, want <= %s", latency.Round(time.Millisecond), canary.MaxLatency)
+			}
+			return nil
+		}
+		if ok {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("canary message not received on %q: %w", queue, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// amqpError prefers the context error once the check deadline closed the
+// connection underneath a pending call.
+func amqpError(ctx context.Context, action string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, amqp.ErrClosed) {
+		return fmt.Errorf("%s: %w", action, ctxErr)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
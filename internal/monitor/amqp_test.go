@@ -0,0 +1,384 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// amqpTestBroker is a minimal AMQP 0-9-1 server covering the handshake,
+// passive queue.declare, basic.publish through the default exchange and
+// basic.get. Credentials are "guest"/"guest".
+type amqpTestBroker struct {
+	mu          sync.Mutex
+	queues      map[string][][]byte
+	consumers   map[string]int
+	dropPublish bool
+}
+
+type amqpTestQueue struct {
+	name     string
+	messages int
+}
+
+func startAMQPBroker(t *testing.T) (*amqpTestBroker, string) {
+	t.Helper()
+
+	broker := &amqpTestBroker{
+		queues:    make(map[string][][]byte),
+		consumers: make(map[string]int),
+	}
+	host, port := startTCPServer(t, broker.serve)
+	return broker, "amqp://guest:guest@" + net.JoinHostPort(host, strconv.Itoa(port)) + "/"
+}
+
+func (b *amqpTestBroker) serve(conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil || string(header) != "AMQP\x00\x00\x09\x01" {
+		return
+	}
+
+	var start amqpTestWriter
+	start.octet(0).octet(9).table().longString("PLAIN").longString("en_US")
+	writeAMQPMethod(conn, 0, 10, 10, start.Bytes())
+
+	var pendingExchange, pendingRoutingKey string
+	var pendingBody []byte
+	var pendingSize uint64
+	for {
+		frameType, channel, payload, err := readAMQPFrame(reader)
+		if err != nil {
+			return
+		}
+		switch frameType {
+		case 2: // content header
+			pendingSize = binary.BigEndian.Uint64(payload[4:12])
+			pendingBody = nil
+			if pendingSize == 0 {
+				b.publish(pendingExchange, pendingRoutingKey, nil)
+			}
+			continue
+		case 3: // content body
+			pendingBody = append(pendingBody, payload...)
+			if uint64(len(pendingBody)) >= pendingSize {
+				b.publish(pendingExchange, pendingRoutingKey, pendingBody)
+			}
+			continue
+		case 8: // heartbeat
+			continue
+		}
+
+		args := amqpTestReader{data: payload[4:]}
+		switch classMethod := binary.BigEndian.Uint32(payload[:4]); classMethod {
+		case 10<<16 | 11: // connection.start-ok
+			args.table()
+			args.shortString()
+			if response := args.longString(); response != "\x00guest\x00guest" {
+				return
+			}
+			var tune amqpTestWriter
+			tune.short(16).long(131072).short(0)
+			writeAMQPMethod(conn, 0, 10, 30, tune.Bytes())
+		case 10<<16 | 31: // connection.tune-ok
+		case 10<<16 | 40: // connection.open
+			writeAMQPMethod(conn, 0, 10, 41, new(amqpTestWriter).shortString("").Bytes())
+		case 10<<16 | 50: // connection.close
+			writeAMQPMethod(conn, 0, 10, 51, nil)
+			return
+		case 20<<16 | 10: // channel.open
+			writeAMQPMethod(conn, channel, 20, 11, new(amqpTestWriter).longString("").Bytes())
+		case 20<<16 | 40: // channel.close
+			writeAMQPMethod(conn, channel, 20, 41, nil)
+		case 20<<16 | 41: // channel.close-ok
+		case 50<<16 | 10: // queue.declare
+			args.short()
+			name := args.shortString()
+			b.mu.Lock()
+			messages, ok := b.queues[name]
+			consumers := b.consumers[name]
+			b.mu.Unlock()
+			if !ok {
+				var closeArgs amqpTestWriter
+				closeArgs.short(404).shortString("NOT_FOUND - no queue '" + name + "'").short(50).short(10)
+				writeAMQPMethod(conn, channel, 20, 40, closeArgs.Bytes())
+				continue
+			}
+			var declareOk amqpTestWriter
+			declareOk.shortString(name).long(uint32(len(messages))).long(uint32(consumers))
+			writeAMQPMethod(conn, channel, 50, 11, declareOk.Bytes())
+		case 60<<16 | 40: // basic.publish
+			args.short()
+			pendingExchange = args.shortString()
+			pendingRoutingKey = args.shortString()
+		case 60<<16 | 70: // basic.get
+			args.short()
+			name := args.shortString()
+			b.mu.Lock()
+			messages := b.queues[name]
+			var body []byte
+			found := len(messages) > 0
+			if found {
+				body = messages[0]
+				b.queues[name] = messages[1:]
+			}
+			remaining := len(b.queues[name])
+			b.mu.Unlock()
+			if !found {
+				writeAMQPMethod(conn, channel, 60, 72, new(amqpTestWriter).shortString("").Bytes())
+				continue
+			}
+			var getOk amqpTestWriter
+			getOk.longLong(1).octet(0).shortString("").shortString(name).long(uint32(remaining))
+			writeAMQPMethod(conn, channel, 60, 71, getOk.Bytes())
+			var contentHeader amqpTestWriter
+			contentHeader.short(60).short(0).longLong(uint64(len(body))).short(0)
+			writeAMQPFrame(conn, 2, channel, contentHeader.Bytes())
+			writeAMQPFrame(conn, 3, channel, body)
+		}
+	}
+}
+
+func (b *amqpTestBroker) publish(exchange, routingKey string, body []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropPublish || exchange != "" {
+		return
+	}
+	if _, ok := b.queues[routingKey]; ok {
+		b.queues[routingKey] = append(b.queues[routingKey], append([]byte(nil), body...))
+	}
+}
+
+func (b *amqpTestBroker) setQueue(queue amqpTestQueue, consumers int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := make([][]byte, queue.messages)
+	for i := range messages {
+		messages[i] = []byte("job-" + strconv.Itoa(i))
+	}
+	b.queues[queue.name] = messages
+	b.consumers[queue.name] = consumers
+}
+
+func readAMQPFrame(reader *bufio.Reader) (byte, uint16, []byte, error) {
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, 0, nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[3:7])+1)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, 0, nil, err
+	}
+	return header[0], binary.BigEndian.Uint16(header[1:3]), payload[:len(payload)-1], nil
+}
+
+func writeAMQPFrame(conn net.Conn, frameType byte, channel uint16, payload []byte) {
+	frame := []byte{frameType}
+	frame = binary.BigEndian.AppendUint16(frame, channel)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	_, _ = conn.Write(append(frame, 0xCE))
+}
+
+func writeAMQPMethod(conn net.Conn, channel, classID, methodID uint16, args []byte) {
+	payload := binary.BigEndian.AppendUint16(nil, classID)
+	payload = binary.BigEndian.AppendUint16(payload, methodID)
+	writeAMQPFrame(conn, 1, channel, append(payload, args...))
+}
+
+type amqpTestWriter struct {
+	bytes.Buffer
+}
+
+func (w *amqpTestWriter) octet(v byte) *amqpTestWriter {
+	w.WriteByte(v)
+	return w
+}
+
+func (w *amqpTestWriter) short(v uint16) *amqpTestWriter {
+	_ = binary.Write(w, binary.BigEndian, v)
+	return w
+}
+
+func (w *amqpTestWriter) long(v uint32) *amqpTestWriter {
+	_ = binary.Write(w, binary.BigEndian, v)
+	return w
+}
+
+func (w *amqpTestWriter) longLong(v uint64) *amqpTestWriter {
+	_ = binary.Write(w, binary.BigEndian, v)
+	return w
+}
+
+func (w *amqpTestWriter) shortString(v string) *amqpTestWriter {
+	w.WriteByte(byte(len(v)))
+	w.WriteString(v)
+	return w
+}
+
+func (w *amqpTestWriter) longString(v string) *amqpTestWriter {
+	w.long(uint32(len(v)))
+	w.WriteString(v)
+	return w
+}
+
+// table writes an empty field table.
+func (w *amqpTestWriter) table() *amqpTestWriter {
+	return w.long(0)
+}
+
+type amqpTestReader struct {
+	data []byte
+}
+
+func (r *amqpTestReader) short() uint16 {
+	v := binary.BigEndian.Uint16(r.data)
+	r.data = r.data[2:]
+	return v
+}
+
+func (r *amqpTestReader) shortString() string {
+	n := int(r.data[0])
+	v := string(r.data[1 : 1+n])
+	r.data = r.data[1+n:]
+	return v
+}
+
+func (r *amqpTestReader) longString() string {
+	n := int(binary.BigEndian.Uint32(r.data))
+	v := string(r.data[4 : 4+n])
+	r.data = r.data[4+n:]
+	return v
+}
+
+func (r *amqpTestReader) table() {
+	r.longString()
+}
+
+func TestValidateAMQPSpecQueueAndCanary(t *testing.T) {
+	broker, url := startAMQPBroker(t)
+	broker.setQueue(amqpTestQueue{name: "orders", messages: 3}, 2)
+	broker.setQueue(amqpTestQueue{name: "eddie.canary", messages: 1}, 0)
+	messagesLTE, consumersGTE := 1000, 1
+
+	err := validateAMQPSpec(context.Background(), spec.Spec{
+		AMQP: &spec.AMQPSpec{
+			Name:    "orders",
+			URL:     url,
+			Timeout: 2 * time.Second,
+			Queue:   "orders",
+			Expect:  spec.AMQPExpect{MessagesLTE: &messagesLTE, ConsumersGTE: &consumersGTE},
+			Canary:  &spec.AMQPCanary{Queue: "eddie.canary", MaxLatency: time.Second},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateAMQPSpec() error = %v, want nil", err)
+	}
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if got := len(broker.queues["eddie.canary"]); got != 0 {
+		t.Fatalf("canary queue holds %d messages after the check, want 0", got)
+	}
+}
+
+func TestValidateAMQPSpecBadCredentials(t *testing.T) {
+	broker, url := startAMQPBroker(t)
+	broker.setQueue(amqpTestQueue{name: "orders"}, 0)
+
+	err := validateAMQPSpec(context.Background(), spec.Spec{
+		AMQP: &spec.AMQPSpec{
+			Name:    "orders",
+			URL:     strings.Replace(url, "guest:guest", "guest:wrong", 1),
+			Timeout: 2 * time.Second,
+			Queue:   "orders",
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "connect:") {
+		t.Fatalf("validateAMQPSpec() error = %v, want connect failure", err)
+	}
+}
+
+func TestValidateAMQPSpecMissingQueue(t *testing.T) {
+	broker, url := startAMQPBroker(t)
+	broker.setQueue(amqpTestQueue{name: "orders"}, 0)
+
+	err := validateAMQPSpec(context.Background(), spec.Spec{
+		AMQP: &spec.AMQPSpec{
+			Name:    "orders",
+			URL:     url,
+			Timeout: 2 * time.Second,
+			Queue:   "payments",
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "NOT_FOUND") {
+		t.Fatalf("validateAMQPSpec() error = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestValidateAMQPSpecTooManyMessages(t *testing.T) {
+	broker, url := startAMQPBroker(t)
+	broker.setQueue(amqpTestQueue{name: "orders", messages: 1500}, 0)
+	messagesLTE := 1000
+
+	err := validateAMQPSpec(context.Background(), spec.Spec{
+		AMQP: &spec.AMQPSpec{
+			Name:    "orders",
+			URL:     url,
+			Timeout: 2 * time.Second,
+			Queue:   "orders",
+			Expect:  spec.AMQPExpect{MessagesLTE: &messagesLTE},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `queue "orders" has 1500 messages, want <= 1000`) {
+		t.Fatalf("validateAMQPSpec() error = %v, want backlog failure", err)
+	}
+}
+
+func TestValidateAMQPSpecNoConsumers(t *testing.T) {
+	broker, url := startAMQPBroker(t)
+	broker.setQueue(amqpTestQueue{name: "orders"}, 0)
+	consumersGTE := 1
+
+	err := validateAMQPSpec(context.Background(), spec.Spec{
+		AMQP: &spec.AMQPSpec{
+			Name:    "orders",
+			URL:     url,
+			Timeout: 2 * time.Second,
+			Queue:   "orders",
+			Expect:  spec.AMQPExpect{ConsumersGTE: &consumersGTE},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `queue "orders" has 0 consumers, want >= 1`) {
+		t.Fatalf("validateAMQPSpec() error = %v, want consumer failure", err)
+	}
+}
+
+func TestValidateAMQPSpecCanaryLost(t *testing.T) {
+	broker, url := startAMQPBroker(t)
+	broker.setQueue(amqpTestQueue{name: "eddie.canary"}, 0)
+	broker.dropPublish = true
+
+	err := validateAMQPSpec(context.Background(), spec.Spec{
+		AMQP: &spec.AMQPSpec{
+			Name:    "orders",
+			URL:     url,
+			Timeout: 300 * time.Millisecond,
+			Canary:  &spec.AMQPCanary{Queue: "eddie.canary"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "canary message not received") {
+		t.Fatalf("validateAMQPSpec() error = %v, want lost canary", err)
+	}
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// buildClientTLSConfig turns spec TLS options into a client tls.Config.
+func buildClientTLSConfig(clientTLS spec.ClientTLS, insecureSkipVerify bool) (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(clientTLS.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify,
+		ServerName:         strings.TrimSpace(clientTLS.ServerName),
+		MinVersion:         minVersion,
+	}
+
+	if caFile := strings.TrimSpace(clientTLS.CAFile); caFile != "" {
+		pemBytes, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("ca_file %q contains no PEM certificates", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	certFile := strings.TrimSpace(clientTLS.ClientCert)
+	keyFile := strings.TrimSpace(clientTLS.ClientKey)
+	if certFile != "" || keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("bad client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// describeTLSError labels handshake failures so that a server certificate the
+// client does not trust can be told apart from a client certificate the
+// server rejected. Other errors are returned unchanged.
+func describeTLSError(err error) error {
+	if err == nil {
+		return nil
+	}
+	// bad_certificate, unsupported_certificate, certificate_revoked,
+	// certificate_expired, certificate_unknown, unknown_ca and
+	// certificate_required, all sent by the server about our certificate.
+	for _, alert := range []tls.AlertError{42, 43, 44, 45, 46, 48, 116} {
+		var received tls.AlertError
+		// net/http does not always keep the alert in the error chain, so the
+		// message is matched as well.
+		if (errors.As(err, &received) && received == alert) ||
+			strings.Contains(err.Error(), "remote error: "+alert.Error()) {
+			return fmt.Errorf("client certificate rejected by server: %w", err)
+		}
+	}
+	var verificationErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &verificationErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return fmt.Errorf("server certificate untrusted: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/miekg/dns"
+)
+
+const resolvConfPath = "/etc/resolv.conf"
+
+func validateDNSSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	dnsSpec := parsedSpec.DNS
+	if dnsSpec == nil {
+		return fmt.Errorf("missing dns spec")
+	}
+
+	query := strings.TrimSpace(dnsSpec.Query)
+	if query == "" {
+		return fmt.Errorf("dns.query is required")
+	}
+
+	recordType := strings.ToUpper(strings.TrimSpace(dnsSpec.Type))
+	if recordType == "" {
+		recordType = "A"
+	}
+	qtype, ok := dns.StringToType[recordType]
+	if !ok {
+		return fmt.Errorf("unsupported dns.type %q", dnsSpec.Type)
+	}
+
+	timeout := dnsSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	server, err := resolveDNSServer(dnsSpec.Server)
+	if err != nil {
+		return err
+	}
+
+	response, err := exchangeDNS(queryCtx, server, dnsSpec.Protocol, query, qtype)
+	if err != nil {
+		return err
+	}
+
+	wantRCode := strings.ToUpper(strings.TrimSpace(dnsSpec.Expect.RCode))
+	if wantRCode == "" {
+		wantRCode = "NOERROR"
+	}
+	gotRCode := dns.RcodeToString[response.Rcode]
+	if gotRCode != wantRCode {
+		return fmt.Errorf("unexpected rcode: got %s, want %s", gotRCode, wantRCode)
+	}
+
+	answers := make([]dns.RR, 0, len(response.Answer))
+	for _, rr := range response.Answer {
+		if rr.Header().Rrtype == qtype {
+			answers = append(answers, rr)
+		}
+	}
+
+	records := make([]string, 0, len(answers))
+	for _, rr := range answers {
+		records = append(records, formatDNSRecord(rr))
+	}
+
+	if len(dnsSpec.Expect.Records) > 0 {
+		want := normalizeDNSRecords(recordType, dnsSpec.Expect.Records)
+		got := normalizeDNSRecords(recordType, records)
+		if strings.Join(want, "\n") != strings.Join(got, "\n") {
+			return fmt.Errorf("unexpected %s records: got %v, want %v", recordType, got, want)
+		}
+	}
+	if len(dnsSpec.Expect.Contains) > 0 {
+		got := normalizeDNSRecords(recordType, records)
+		for _, expected := range normalizeDNSRecords(recordType, dnsSpec.Expect.Contains) {
+			if !containsString(got, expected) {
+				return fmt.Errorf("%s records %v do not contain %q", recordType, got, expected)
+			}
+		}
+	}
+	if dnsSpec.Expect.MinTTL != nil {
+		if len(answers) == 0 {
+			return fmt.Errorf("no %s records to check ttl", recordType)
+		}
+		minTTL := uint32(*dnsSpec.Expect.MinTTL)
+		for _, rr := range answers {
+			if rr.Header().Ttl < minTTL {
+				return fmt.Errorf("record ttl too low: %q has ttl %d, want >= %d", formatDNSRecord(rr), rr.Header().Ttl, minTTL)
+			}
+		}
+	}
+
+	return nil
+}
+
+func exchangeDNS(ctx context.Context, server, protocol, query string, qtype uint16) (*dns.Msg, error) {
+	network := strings.ToLower(strings.TrimSpace(protocol))
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported dns.protocol %q", protocol)
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(query), qtype)
+	msg.RecursionDesired = true
+
+	client := &dns.Client{Net: network}
+	response, _, err := client.ExchangeContext(ctx, msg, server)
+	if err != nil {
+		return nil, fmt.Errorf("dns query %s %s via %s/%s: %w", dns.TypeToString[qtype], query, network, server, err)
+	}
+	if response.Truncated && network == "udp" {
+		client.Net = "tcp"
+		response, _, err = client.ExchangeContext(ctx, msg, server)
+		if err != nil {
+			return nil, fmt.Errorf("dns query %s %s via tcp/%s after truncation: %w", dns.TypeToString[qtype], query, server, err)
+		}
+	}
+	return response, nil
+}
+
+func resolveDNSServer(raw string) (string, error) {
+	server := strings.TrimSpace(raw)
+	if server == "" {
+		clientConfig, err := dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil {
+			return "", fmt.Errorf("read system resolver config: %w", err)
+		}
+		if len(clientConfig.Servers) == 0 {
+			return "", fmt.Errorf("no nameservers in %s", resolvConfPath)
+		}
+		return net.JoinHostPort(clientConfig.Servers[0], clientConfig.Port), nil
+	}
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server, nil
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), "53"), nil
+}
+
+func formatDNSRecord(rr dns.RR) string {
+	switch record := rr.(type) {
+	case *dns.A:
+		return record.A.String()
+	case *dns.AAAA:
+		return record.AAAA.String()
+	case *dns.CNAME:
+		return record.Target
+	case *dns.NS:
+		return record.Ns
+	case *dns.MX:
+		return strconv.Itoa(int(record.Preference)) + " " + record.Mx
+	case *dns.TXT:
+		return strings.Join(record.Txt, "")
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", record.Priority, record.Weight, record.Port, record.Target)
+	case *dns.CAA:
+		return fmt.Sprintf("%d %s %q", record.Flag, record.Tag, record.Value)
+	default:
+		header := rr.Header().String()
+		return strings.TrimSpace(strings.TrimPrefix(rr.String(), header))
+	}
+}
+
+func normalizeDNSRecords(recordType string, records []string) []string {
+	normalized := make([]string, 0, len(records))
+	for _, record := range records {
+		normalized = append(normalized, normalizeDNSRecord(recordType, record))
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// normalizeDNSRecord makes names comparable (case, trailing dot) while keeping
+// free-form TXT and CAA values untouched.
+func normalizeDNSRecord(recordType, record string) string {
+	trimmed := strings.TrimSpace(record)
+	switch recordType {
+	case "TXT", "CAA":
+		return trimmed
+	}
+	fields := strings.Fields(trimmed)
+	for idx, field := range fields {
+		fields[idx] = strings.ToLower(strings.TrimSuffix(field, "."))
+	}
+	return strings.Join(fields, " ")
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/miekg/dns"
+)
+
+func TestValidateDNSSpecRecordsAndTTL(t *testing.T) {
+	server := startDNSStub(t, "udp", map[string][]string{
+		"example.test. A": {
+			"example.test. 300 IN A 192.0.2.10",
+			"example.test. 300 IN A 192.0.2.11",
+		},
+	})
+
+	minTTL := 120
+	err := validateDNSSpec(context.Background(), spec.Spec{
+		DNS: &spec.DNSSpec{
+			Name:    "example-a",
+			Server:  server,
+			Query:   "example.test",
+			Type:    "A",
+			Timeout: 2 * time.Second,
+			Expect: spec.DNSExpect{
+				Records: []string{"192.0.2.11", "192.0.2.10"},
+				MinTTL:  &minTTL,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateDNSSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateDNSSpecRejectsLowTTL(t *testing.T) {
+	server := startDNSStub(t, "udp", map[string][]string{
+		"example.test. A": {"example.test. 30 IN A 192.0.2.10"},
+	})
+
+	minTTL := 60
+	err := validateDNSSpec(context.Background(), spec.Spec{
+		DNS: &spec.DNSSpec{
+			Name:    "example-ttl",
+			Server:  server,
+			Query:   "example.test",
+			Timeout: 2 * time.Second,
+			Expect: spec.DNSExpect{
+				MinTTL: &minTTL,
+			},
+		},
+	})
+	if err == nil {
+		t.Fatalf("validateDNSSpec() error = nil, want ttl failure")
+	}
+	if !strings.Contains(err.Error(), "ttl too low") {
+		t.Fatalf("validateDNSSpec() error = %v, want ttl message", err)
+	}
+}
+
+func TestValidateDNSSpecContainsOverTCP(t *testing.T) {
+	server := startDNSStub(t, "tcp", map[string][]string{
+		"example.test. MX": {
+			"example.test. 300 IN MX 10 mx1.example.test.",
+			"example.test. 300 IN MX 20 mx2.example.test.",
+		},
+	})
+
+	err := validateDNSSpec(context.Background(), spec.Spec{
+		DNS: &spec.DNSSpec{
+			Name:     "example-mx",
+			Server:   server,
+			Protocol: "tcp",
+			Query:    "example.test",
+			Type:     "mx",
+			Timeout:  2 * time.Second,
+			Expect: spec.DNSExpect{
+				Contains: []string{"10 MX1.example.test."},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateDNSSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateDNSSpecRCode(t *testing.T) {
+	server := startDNSStub(t, "udp", map[string][]string{})
+
+	err := validateDNSSpec(context.Background(), spec.Spec{
+		DNS: &spec.DNSSpec{
+			Name:    "missing",
+			Server:  server,
+			Query:   "missing.example.test",
+			Timeout: 2 * time.Second,
+		},
+	})
+	if err == nil {
+		t.Fatalf("validateDNSSpec() error = nil, want rcode failure")
+	}
+	if !strings.Contains(err.Error(), "NXDOMAIN") {
+		t.Fatalf("validateDNSSpec() error = %v, want NXDOMAIN", err)
+	}
+
+	err = validateDNSSpec(context.Background(), spec.Spec{
+		DNS: &spec.DNSSpec{
+			Name:    "missing-expected",
+			Server:  server,
+			Query:   "missing.example.test",
+			Timeout: 2 * time.Second,
+			Expect: spec.DNSExpect{
+				RCode: "nxdomain",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateDNSSpec() error = %v, want nil", err)
+	}
+}
+
+func TestNormalizeDNSRecord(t *testing.T) {
+	if got := normalizeDNSRecord("CNAME", " Target.Example.COM. "); got != "target.example.com" {
+		t.Fatalf("normalizeDNSRecord(CNAME) = %q", got)
+	}
+	if got := normalizeDNSRecord("TXT", "v=spf1 Include:_spf.example.com ~all"); got != "v=spf1 Include:_spf.example.com ~all" {
+		t.Fatalf("normalizeDNSRecord(TXT) = %q", got)
+	}
+}
+
+func TestResolveDNSServerDefaultsPort(t *testing.T) {
+	got, err := resolveDNSServer("192.0.2.53")
+	if err != nil {
+		t.Fatalf("resolveDNSServer() error = %v", err)
+	}
+	if got != "192.0.2.53:53" {
+		t.Fatalf("resolveDNSServer() = %q, want %q", got, "192.0.2.53:53")
+	}
+	got, err = resolveDNSServer("[2001:db8::53]:5353")
+	if err != nil {
+		t.Fatalf("resolveDNSServer() error = %v", err)
+	}
+	if got != "[2001:db8::53]:5353" {
+		t.Fatalf("resolveDNSServer() = %q, want %q", got, "[2001:db8::53]:5353")
+	}
+}
+
+// startDNSStub serves fixed answers keyed by "<fqdn> <TYPE>" and NXDOMAIN otherwise.
+func startDNSStub(t *testing.T, network string, zone map[string][]string) string {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		reply := new(dns.Msg)
+		reply.SetReply(req)
+		question := req.Question[0]
+		records, ok := zone[strings.ToLower(question.Name)+" "+dns.TypeToString[question.Qtype]]
+		if !ok {
+			reply.Rcode = dns.RcodeNameError
+		}
+		for _, record := range records {
+			rr, err := dns.NewRR(record)
+			if err != nil {
+				t.Errorf("dns.NewRR(%q) error = %v", record, err)
+				continue
+			}
+			reply.Answer = append(reply.Answer, rr)
+		}
+		_ = w.WriteMsg(reply)
+	})
+
+	started := make(chan struct{})
+	server := &dns.Server{Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	switch network {
+	case "tcp":
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.Listen() error = %v", err)
+		}
+		server.Listener = listener
+	default:
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.ListenPacket() error = %v", err)
+		}
+		server.PacketConn = conn
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	if server.Listener != nil {
+		return server.Listener.Addr().String()
+	}
+	return server.PacketConn.LocalAddr().String()
+}
@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/miekg/dns"
+)
+
+func validateDNSBLSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	dnsblSpec := parsedSpec.DNSBL
+	if dnsblSpec == nil {
+		return fmt.Errorf("missing dnsbl spec")
+	}
+
+	timeout := dnsblSpec.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	server, err := resolveDNSServer(dnsblSpec.Server)
+	if err != nil {
+		return err
+	}
+
+	ips := make([]net.IP, 0, len(dnsblSpec.Targets))
+	for _, target := range dnsblSpec.Targets {
+		resolved, err := resolveDNSBLTarget(queryCtx, server, dnsblSpec.Protocol, strings.TrimSpace(target))
+		if err != nil {
+			return err
+		}
+		ips = append(ips, resolved...)
+	}
+
+	var listings []string
+	for _, ip := range ips {
+		for _, rawZone := range dnsblSpec.Zones {
+			zone := strings.Trim(strings.TrimSpace(rawZone), ".")
+			listing, err := lookupDNSBL(queryCtx, server, dnsblSpec.Protocol, ip, zone)
+			if err != nil {
+				return err
+			}
+			if listing != "" {
+				listings = append(listings, listing)
+			}
+		}
+	}
+	if len(listings) > 0 {
+		return fmt.Errorf("listed on %d blocklist(s): %s", len(listings), strings.Join(listings, "; "))
+	}
+	return nil
+}
+
+// resolveDNSBLTarget returns the IP itself or the A and AAAA records of a
+// hostname, resolved through the configured server.
+func resolveDNSBLTarget(ctx context.Context, server, protocol, target string) ([]net.IP, error) {
+	if ip := net.ParseIP(target); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	var ips []net.IP
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		response, err := exchangeDNS(ctx, server, protocol, target, qtype)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range response.Answer {
+			switch record := rr.(type) {
+			case *dns.A:
+				ips = append(ips, record.A)
+			case *dns.AAAA:
+				ips = append(ips, record.AAAA)
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("target %s resolves to no addresses", target)
+	}
+	return ips, nil
+}
+
+// lookupDNSBL returns a description of the listing, or "" when ip is not
+// listed in zone.
+func lookupDNSBL(ctx context.Context, server, protocol string, ip net.IP, zone string) (string, error) {
+	query := reverseDNSBLName(ip) + "." + zone
+	response, err := exchangeDNS(ctx, server, protocol, query, dns.TypeA)
+	if err != nil {
+		return "", err
+	}
+	switch response.Rcode {
+	case dns.RcodeNameError:
+		return "", nil
+	case dns.RcodeSuccess:
+	default:
+		return "", fmt.Errorf("dnsbl query %s: rcode %s", query, dns.RcodeToString[response.Rcode])
+	}
+
+	var codes []string
+	for _, rr := range response.Answer {
+		record, ok := rr.(*dns.A)
+		if !ok {
+			continue
+		}
+		// Spamhaus and others answer 127.255.255.x to refuse queries, e.g.
+		// from public resolvers; that is an error, not a listing.
+		if record.A.To4() != nil && record.A.To4()[1] == 255 && record.A.To4()[2] == 255 {
+			return "", fmt.Errorf("dnsbl %s refused the query with %s; use a dedicated resolver", zone, record.A)
+		}
+		codes = append(codes, record.A.String())
+	}
+	if len(codes) == 0 {
+		return "", nil
+	}
+
+	listing := fmt.Sprintf("%s on %s (%s)", ip, zone, strings.Join(codes, ", "))
+	txtResponse, err := exchangeDNS(ctx, server, protocol, query, dns.TypeTXT)
+	if err == nil {
+		var reasons []string
+		for _, rr := range txtResponse.Answer {
+			if record, ok := rr.(*dns.TXT); ok {
+				reasons = append(reasons, strconv.Quote(strings.Join(record.Txt, "")))
+			}
+		}
+		if len(reasons) > 0 {
+			listing += ": " + strings.Join(reasons, ", ")
+		}
+	}
+	return listing, nil
+}
+
+// reverseDNSBLName reverses IPv4 octets or IPv6 nibbles for DNSBL queries.
+func reverseDNSBLName(ip net.IP) string {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", ipv4[3], ipv4[2], ipv4[1], ipv4[0])
+	}
+	ipv6 := ip.To16()
+	nibbles := make([]string, 0, 32)
+	for idx := len(ipv6) - 1; idx >= 0; idx-- {
+		nibbles = append(nibbles, strconv.FormatUint(uint64(ipv6[idx]&0x0f), 16), strconv.FormatUint(uint64(ipv6[idx]>>4), 16))
+	}
+	return strings.Join(nibbles, ".")
+}
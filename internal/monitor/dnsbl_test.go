@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func TestValidateDNSBLSpecListing(t *testing.T) {
+	server := startDNSStub(t, "udp", map[string][]string{
+		"mx.example.test. A":                 {"mx.example.test. 300 IN A 192.0.2.10"},
+		"mx.example.test. AAAA":              {},
+		"10.2.0.192.bl.example.test. A":      {"10.2.0.192.bl.example.test. 300 IN A 127.0.0.2"},
+		"10.2.0.192.bl.example.test. TXT":    {`10.2.0.192.bl.example.test. 300 IN TXT "Listed for spam, see https://bl.example.test/192.0.2.10"`},
+		"10.2.0.192.other.example.test. A":   {"10.2.0.192.other.example.test. 300 IN A 127.0.0.4"},
+		"10.2.0.192.other.example.test. TXT": {},
+	})
+
+	err := validateDNSBLSpec(context.Background(), spec.Spec{
+		DNSBL: &spec.DNSBLSpec{
+			Name:    "outbound",
+			Targets: []string{"mx.example.test", "192.0.2.20"},
+			Zones:   []string{"bl.example.test", "other.example.test.", "clean.example.test"},
+			Server:  server,
+			Timeout: 2 * time.Second,
+		},
+	})
+	if err == nil {
+		t.Fatalf("validateDNSBLSpec() error = nil, want listing")
+	}
+	for _, want := range []string{
+		"listed on 2 blocklist(s)",
+		`192.0.2.10 on bl.example.test (127.0.0.2): "Listed for spam, see https://bl.example.test/192.0.2.10"`,
+		"192.0.2.10 on other.example.test (127.0.0.4)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("validateDNSBLSpec() error = %v, want %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "192.0.2.20") {
+		t.Fatalf("validateDNSBLSpec() error = %v, want 192.0.2.20 unlisted", err)
+	}
+}
+
+func TestValidateDNSBLSpecClean(t *testing.T) {
+	server := startDNSStub(t, "tcp", map[string][]string{})
+
+	err := validateDNSBLSpec(context.Background(), spec.Spec{
+		DNSBL: &spec.DNSBLSpec{
+			Name:     "outbound",
+			Targets:  []string{"192.0.2.20", "2001:db8::1"},
+			Zones:    []string{"bl.example.test"},
+			Server:   server,
+			Protocol: "tcp",
+			Timeout:  2 * time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateDNSBLSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateDNSBLSpecRefusedQuery(t *testing.T) {
+	server := startDNSStub(t, "udp", map[string][]string{
+		"20.2.0.192.bl.example.test. A": {"20.2.0.192.bl.example.test. 300 IN A 127.255.255.254"},
+	})
+
+	err := validateDNSBLSpec(context.Background(), spec.Spec{
+		DNSBL: &spec.DNSBLSpec{
+			Name:    "outbound",
+			Targets: []string{"192.0.2.20"},
+			Zones:   []string{"bl.example.test"},
+			Server:  server,
+			Timeout: 2 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "refused the query") {
+		t.Fatalf("validateDNSBLSpec() error = %v, want refused query", err)
+	}
+}
+
+func TestReverseDNSBLName(t *testing.T) {
+	if got := reverseDNSBLName(net.ParseIP("192.0.2.10")); got != "10.2.0.192" {
+		t.Fatalf("reverseDNSBLName(ipv4) = %q", got)
+	}
+	want := "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2"
+	if got := reverseDNSBLName(net.ParseIP("2001:db8::1")); got != want {
+		t.Fatalf("reverseDNSBLName(ipv6) = %q, want %q", got, want)
+	}
+}
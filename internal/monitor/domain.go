@@ -0,0 +1,242 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+const rdapBootstrapTTL = 24 * time.Hour
+
+// rdapBootstrapURL is the IANA registry of RDAP servers per TLD.
+var rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+var rdapBootstrap = struct {
+	sync.Mutex
+	source    string
+	fetchedAt time.Time
+	servers   map[string]string
+}{}
+
+type rdapDomain struct {
+	Events []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+	Entities []struct {
+		Roles      []string `json:"roles"`
+		VCardArray []any    `json:"vcardArray"`
+	} `json:"entities"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+}
+
+func validateDomainSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	domainSpec := parsedSpec.Domain
+	if domainSpec == nil {
+		return fmt.Errorf("missing domain spec")
+	}
+
+	domain := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domainSpec.Domain), "."))
+	timeout := domainSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	server := strings.TrimSpace(domainSpec.RDAPServer)
+	if server == "" {
+		var err error
+		server, err = lookupRDAPServer(ctx, client, domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	record, err := fetchRDAPDomain(ctx, client, server, domain)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := record.expiration()
+	if err != nil && domainSpec.MinDaysValid != nil {
+		return err
+	}
+	if err == nil {
+		days := 0
+		if domainSpec.MinDaysValid != nil {
+			days = *domainSpec.MinDaysValid
+		}
+		cutoff := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+		if !expiresAt.After(cutoff) {
+			return fmt.Errorf("domain %s expires too soon: expiration=%s", domain, expiresAt.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if want := strings.TrimSpace(domainSpec.Expect.Registrar); want != "" {
+		registrar := record.registrar()
+		if !strings.Contains(strings.ToLower(registrar), strings.ToLower(want)) {
+			return fmt.Errorf("registrar %q does not contain %q", registrar, want)
+		}
+	}
+	if len(domainSpec.Expect.Nameservers) > 0 {
+		actual := make([]string, 0, len(record.Nameservers))
+		for _, nameserver := range record.Nameservers {
+			actual = append(actual, normalizeNameserver(nameserver.LDHName))
+		}
+		for _, want := range domainSpec.Expect.Nameservers {
+			if !containsString(actual, normalizeNameserver(want)) {
+				return fmt.Errorf("nameserver %q not delegated (got %s)", want, strings.Join(actual, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeNameserver(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+func (d rdapDomain) expiration() (time.Time, error) {
+	for _, event := range d.Events {
+		if event.Action != "expiration" {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, event.Date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse expiration date %q: %w", event.Date, err)
+		}
+		return expiresAt, nil
+	}
+	return time.Time{}, fmt.Errorf("rdap response has no expiration event")
+}
+
+// registrar returns the formatted name (vCard "fn") of the registrar entity.
+func (d rdapDomain) registrar() string {
+	for _, entity := range d.Entities {
+		if !containsString(entity.Roles, "registrar") || len(entity.VCardArray) < 2 {
+			continue
+		}
+		properties, ok := entity.VCardArray[1].([]any)
+		if !ok {
+			continue
+		}
+		for _, raw := range properties {
+			property, ok := raw.([]any)
+			if !ok || len(property) < 4 || property[0] != "fn" {
+				continue
+			}
+			if name, ok := property[3].(string); ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// lookupRDAPServer finds the RDAP base URL for the domain's TLD in the IANA
+// bootstrap registry, which is cached for a day.
+func lookupRDAPServer(ctx context.Context, client *http.Client, domain string) (string, error) {
+	source := rdapBootstrapURL
+	rdapBootstrap.Lock()
+	servers := rdapBootstrap.servers
+	if rdapBootstrap.source != source || time.Since(rdapBootstrap.fetchedAt) > rdapBootstrapTTL {
+		servers = nil
+	}
+	rdapBootstrap.Unlock()
+
+	// Fetch without holding the lock so a slow registry cannot stall other
+	// domain checks; concurrent refreshes simply race to store the result.
+	if servers == nil {
+		fetched, err := fetchRDAPBootstrap(ctx, client, source)
+		if err != nil {
+			return "", err
+		}
+		servers = fetched
+		rdapBootstrap.Lock()
+		rdapBootstrap.servers = servers
+		rdapBootstrap.source = source
+		rdapBootstrap.fetchedAt = time.Now()
+		rdapBootstrap.Unlock()
+	}
+
+	labels := strings.Split(domain, ".")
+	for idx := 1; idx < len(labels); idx++ {
+		if server, ok := servers[strings.Join(labels[idx:], ".")]; ok {
+			return server, nil
+		}
+	}
+	return "", fmt.Errorf("no rdap server registered for %s; set domain.rdap_server", domain)
+}
+
+func fetchRDAPBootstrap(ctx context.Context, client *http.Client, bootstrapURL string) (map[string]string, error) {
+	var registry struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := getRDAPJSON(ctx, client, bootstrapURL, &registry); err != nil {
+		return nil, fmt.Errorf("rdap bootstrap: %w", err)
+	}
+	servers := make(map[string]string)
+	for _, service := range registry.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		server := service[1][0]
+		for _, candidate := range service[1] {
+			if strings.HasPrefix(candidate, "https://") {
+				server = candidate
+				break
+			}
+		}
+		for _, tld := range service[0] {
+			servers[strings.ToLower(tld)] = server
+		}
+	}
+	return servers, nil
+}
+
+func fetchRDAPDomain(ctx context.Context, client *http.Client, server, domain string) (rdapDomain, error) {
+	var record rdapDomain
+	lookupURL := strings.TrimRight(server, "/") + "/domain/" + domain
+	if err := getRDAPJSON(ctx, client, lookupURL, &record); err != nil {
+		return rdapDomain{}, fmt.Errorf("rdap lookup: %w", err)
+	}
+	return record, nil
+}
+
+func getRDAPJSON(ctx context.Context, client *http.Client, targetURL string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: not found", targetURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", targetURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("decode %s: %w", targetURL, err)
+	}
+	return nil
+}
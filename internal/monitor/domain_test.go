@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// startRDAPServer serves an IANA style bootstrap file at /dns.json that
+// points "test" to itself, and example.test with the given expiration.
+func startRDAPServer(t *testing.T, expiresAt time.Time) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			_, _ = fmt.Fprintf(w, `{"version":"1.0","services":[[["org"],["https://rdap.example.org/"]],[["test"],[%q]]]}`, server.URL+"/rdap/")
+		case "/rdap/domain/example.test":
+			w.Header().Set("Content-Type", "application/rdap+json")
+			_, _ = fmt.Fprintf(w, `{
+				"objectClassName": "domain",
+				"ldhName": "EXAMPLE.TEST",
+				"events": [
+					{"eventAction": "registration", "eventDate": "2001-05-01T00:00:00Z"},
+					{"eventAction": "expiration", "eventDate": %q}
+				],
+				"entities": [{
+					"objectClassName": "entity",
+					"roles": ["registrar"],
+					"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Registrar, Inc."]]]
+				}],
+				"nameservers": [
+					{"objectClassName": "nameserver", "ldhName": "NS1.EXAMPLE.NET"},
+					{"objectClassName": "nameserver", "ldhName": "ns2.example.net"}
+				]
+			}`, expiresAt.UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func useRDAPBootstrap(t *testing.T, bootstrapURL string) {
+	t.Helper()
+
+	previous := rdapBootstrapURL
+	rdapBootstrapURL = bootstrapURL
+	t.Cleanup(func() {
+		rdapBootstrapURL = previous
+	})
+}
+
+func TestValidateDomainSpecBootstrap(t *testing.T) {
+	server := startRDAPServer(t, time.Now().Add(90*24*time.Hour))
+	useRDAPBootstrap(t, server.URL+"/dns.json")
+
+	minDays := 30
+	err := validateDomainSpec(context.Background(), spec.Spec{
+		Domain: &spec.DomainSpec{
+			Name:         "example",
+			Domain:       "Example.Test.",
+			Timeout:      2 * time.Second,
+			MinDaysValid: &minDays,
+			Expect: spec.DomainExpect{
+				Registrar:   "example registrar",
+				Nameservers: []string{"ns1.example.net.", "ns2.example.net"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateDomainSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateDomainSpecExpiresSoon(t *testing.T) {
+	server := startRDAPServer(t, time.Now().Add(10*24*time.Hour))
+
+	minDays := 30
+	err := validateDomainSpec(context.Background(), spec.Spec{
+		Domain: &spec.DomainSpec{
+			Name:         "example",
+			Domain:       "example.test",
+			RDAPServer:   server.URL + "/rdap",
+			Timeout:      2 * time.Second,
+			MinDaysValid: &minDays,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "expires too soon") {
+		t.Fatalf("validateDomainSpec() error = %v, want expiry failure", err)
+	}
+}
+
+func TestValidateDomainSpecRegistrarMismatch(t *testing.T) {
+	server := startRDAPServer(t, time.Now().Add(90*24*time.Hour))
+
+	err := validateDomainSpec(context.Background(), spec.Spec{
+		Domain: &spec.DomainSpec{
+			Name:       "example",
+			Domain:     "example.test",
+			RDAPServer: server.URL + "/rdap/",
+			Timeout:    2 * time.Second,
+			Expect:     spec.DomainExpect{Registrar: "Other Registrar"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not contain") {
+		t.Fatalf("validateDomainSpec() error = %v, want registrar mismatch", err)
+	}
+}
+
+func TestValidateDomainSpecNameserverNotDelegated(t *testing.T) {
+	server := startRDAPServer(t, time.Now().Add(90*24*time.Hour))
+
+	err := validateDomainSpec(context.Background(), spec.Spec{
+		Domain: &spec.DomainSpec{
+			Name:       "example",
+			Domain:     "example.test",
+			RDAPServer: server.URL + "/rdap/",
+			Timeout:    2 * time.Second,
+			Expect:     spec.DomainExpect{Nameservers: []string{"ns3.example.net"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not delegated") {
+		t.Fatalf("validateDomainSpec() error = %v, want nameserver mismatch", err)
+	}
+}
+
+func TestValidateDomainSpecNotFound(t *testing.T) {
+	server := startRDAPServer(t, time.Now().Add(90*24*time.Hour))
+
+	err := validateDomainSpec(context.Background(), spec.Spec{
+		Domain: &spec.DomainSpec{
+			Name:       "example",
+			Domain:     "missing.test",
+			RDAPServer: server.URL + "/rdap/",
+			Timeout:    2 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("validateDomainSpec() error = %v, want not found", err)
+	}
+}
+
+func TestValidateDomainSpecUnknownTLD(t *testing.T) {
+	server := startRDAPServer(t, time.Now().Add(90*24*time.Hour))
+	useRDAPBootstrap(t, server.URL+"/dns.json")
+
+	err := validateDomainSpec(context.Background(), spec.Spec{
+		Domain: &spec.DomainSpec{Name: "example", Domain: "example.invalid", Timeout: 2 * time.Second},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no rdap server registered") {
+		t.Fatalf("validateDomainSpec() error = %v, want bootstrap miss", err)
+	}
+}
+
+func TestLookupRDAPServerDoesNotBlockOnPendingBootstrap(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		_, _ = fmt.Fprint(w, `{"services":[]}`)
+	}))
+	defer server.Close()
+	defer close(release)
+	useRDAPBootstrap(t, server.URL+"/dns.json")
+
+	go func() {
+		_, _ = lookupRDAPServer(context.Background(), server.Client(), "example.test")
+	}()
+	<-requested
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := lookupRDAPServer(ctx, server.Client(), "example.test")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Fatalf("lookupRDAPServer() error = %v, want context canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookupRDAPServer() blocked behind a pending bootstrap fetch")
+	}
+}
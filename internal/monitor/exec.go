@@ -0,0 +1,290 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// Nagios/Monitoring-Plugins exit codes.
+const (
+	execStateOK       = 0
+	execStateWarning  = 1
+	execStateCritical = 2
+	execStateUnknown  = 3
+)
+
+// execWaitDelay bounds how long a finished or killed plugin may keep its
+// output pipes open through background children.
+const execWaitDelay = 2 * time.Second
+
+// execCheckError reports a non-OK plugin result with its output.
+type execCheckError struct {
+	State    string
+	Summary  string
+	Output   string
+	Perfdata []perfdataItem
+}
+
+func (e *execCheckError) Error() string {
+	if e.Summary == "" {
+		return e.State
+	}
+	return e.State + ": " + e.Summary
+}
+
+// Details returns the plugin output and parsed perfdata for notifications.
+func (e *execCheckError) Details() string {
+	var b strings.Builder
+	if output := strings.TrimSpace(e.Output); output != "" {
+		b.WriteString("output:\n")
+		b.WriteString(output)
+		b.WriteString("\n")
+	}
+	if len(e.Perfdata) > 0 {
+		b.WriteString("perfdata:\n")
+		for _, item := range e.Perfdata {
+			b.WriteString("  ")
+			b.WriteString(item.String())
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// perfdataItem is one parsed 'label'=value[UOM];[warn];[crit];[min];[max] entry.
+type perfdataItem struct {
+	Label string
+	Value string
+	UOM   string
+	Warn  string
+	Crit  string
+	Min   string
+	Max   string
+}
+
+func (p perfdataItem) String() string {
+	text := fmt.Sprintf("%s=%s%s", p.Label, p.Value, p.UOM)
+	thresholds := []string{p.Warn, p.Crit, p.Min, p.Max}
+	last := -1
+	for idx, value := range thresholds {
+		if value != "" {
+			last = idx
+		}
+	}
+	if last >= 0 {
+		text += ";" + strings.Join(thresholds[:last+1], ";")
+	}
+	return text
+}
+
+func validateExecSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	execSpec := parsedSpec.Exec
+	if execSpec == nil {
+		return fmt.Errorf("missing exec spec")
+	}
+
+	command := strings.TrimSpace(execSpec.Command)
+	if command == "" {
+		return fmt.Errorf("exec.command is required")
+	}
+
+	timeout := execSpec.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, command, execSpec.Args...)
+	cmd.Dir = strings.TrimSpace(execSpec.WorkingDir)
+	cmd.Env = execEnvironment(execSpec.Env)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Run the plugin in its own process group so a timeout also kills the
+	// children it started.
+	startExecProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killExecProcessGroup(cmd)
+	}
+	cmd.WaitDelay = execWaitDelay
+
+	runErr := cmd.Run()
+	if errors.Is(runErr, exec.ErrWaitDelay) {
+		// The plugin exited but left children holding its output open.
+		_ = killExecProcessGroup(cmd)
+		runErr = nil
+	}
+	output := stdout.String()
+	if strings.TrimSpace(output) == "" {
+		output = stderr.String()
+	}
+	summary, perfdata := parsePluginOutput(output)
+
+	if execCtx.Err() == context.DeadlineExceeded {
+		return &execCheckError{
+			State:    "UNKNOWN",
+			Summary:  fmt.Sprintf("plugin timed out after %s", timeout),
+			Output:   output,
+			Perfdata: perfdata,
+		}
+	}
+
+	exitCode := execStateOK
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return fmt.Errorf("run %q: %w", command, runErr)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	state := ""
+	switch exitCode {
+	case execStateOK:
+		return nil
+	case execStateWarning:
+		if execSpec.AllowWarning {
+			return nil
+		}
+		state = "WARNING"
+	case execStateCritical:
+		state = "CRITICAL"
+	case execStateUnknown:
+		state = "UNKNOWN"
+	default:
+		state = "UNKNOWN"
+		if summary == "" {
+			summary = fmt.Sprintf("unexpected exit code %d", exitCode)
+		} else {
+			summary = fmt.Sprintf("unexpected exit code %d: %s", exitCode, summary)
+		}
+	}
+
+	return &execCheckError{
+		State:    state,
+		Summary:  summary,
+		Output:   output,
+		Perfdata: perfdata,
+	}
+}
+
+func execEnvironment(overrides map[string]string) []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+overrides[key])
+	}
+	return env
+}
+
+// parsePluginOutput splits plugin output into the first-line text and the
+// perfdata found after "|" on the first line and in the long output.
+func parsePluginOutput(output string) (string, []perfdataItem) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	summary := ""
+	perfText := make([]string, 0, 2)
+
+	if len(lines) > 0 {
+		first := lines[0]
+		if idx := strings.Index(first, "|"); idx >= 0 {
+			perfText = append(perfText, first[idx+1:])
+			first = first[:idx]
+		}
+		summary = strings.TrimSpace(first)
+	}
+
+	inPerfdata := false
+	for _, line := range lines[1:] {
+		if inPerfdata {
+			perfText = append(perfText, line)
+			continue
+		}
+		if idx := strings.Index(line, "|"); idx >= 0 {
+			perfText = append(perfText, line[idx+1:])
+			inPerfdata = true
+		}
+	}
+
+	perfdata := make([]perfdataItem, 0)
+	for _, text := range perfText {
+		perfdata = append(perfdata, parsePerfdata(text)...)
+	}
+	return summary, perfdata
+}
+
+func parsePerfdata(raw string) []perfdataItem {
+	items := make([]perfdataItem, 0)
+	for _, token := range splitPerfdataTokens(raw) {
+		eq := strings.LastIndex(token, "=")
+		if eq <= 0 {
+			continue
+		}
+		label := strings.Trim(token[:eq], "'")
+		fields := strings.Split(token[eq+1:], ";")
+		value, uom := splitPerfdataValue(fields[0])
+		if label == "" || value == "" {
+			continue
+		}
+		item := perfdataItem{Label: label, Value: value, UOM: uom}
+		optional := []*string{&item.Warn, &item.Crit, &item.Min, &item.Max}
+		for idx, field := range fields[1:] {
+			if idx >= len(optional) {
+				break
+			}
+			*optional[idx] = strings.TrimSpace(field)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// splitPerfdataTokens splits on whitespace while keeping quoted labels intact.
+func splitPerfdataTokens(raw string) []string {
+	tokens := make([]string, 0)
+	var current strings.Builder
+	quoted := false
+	for _, r := range raw {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t') && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+func splitPerfdataValue(raw string) (string, string) {
+	value := strings.TrimSpace(raw)
+	end := len(value)
+	for idx, r := range value {
+		if (r < '0' || r > '9') && r != '.' && r != '-' && r != '+' && r != 'e' && r != 'E' && r != 'U' {
+			end = idx
+			break
+		}
+	}
+	return value[:end], value[end:]
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package monitor
+
+import "os/exec"
+
+// startExecProcessGroup is a no-op where process groups are unavailable.
+func startExecProcessGroup(*exec.Cmd) {}
+
+// killExecProcessGroup kills only the plugin process itself.
+func killExecProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
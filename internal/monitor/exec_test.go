@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func TestValidateExecSpecExitCodes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		script       string
+		allowWarning bool
+		wantState    string
+	}{
+		{name: "ok", script: "echo 'OK - all good'; exit 0"},
+		{name: "warning", script: "echo 'WARNING - almost full'; exit 1", wantState: "WARNING"},
+		{name: "warning allowed", script: "echo 'WARNING - almost full'; exit 1", allowWarning: true},
+		{name: "critical", script: "echo 'CRITICAL - full'; exit 2", wantState: "CRITICAL"},
+		{name: "unknown", script: "echo 'UNKNOWN - no data'; exit 3", wantState: "UNKNOWN"},
+		{name: "unexpected", script: "exit 42", wantState: "UNKNOWN"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateExecSpec(context.Background(), spec.Spec{
+				Exec: &spec.ExecSpec{
+					Name:         tc.name,
+					Command:      "sh",
+					Args:         []string{"-c", tc.script},
+					AllowWarning: tc.allowWarning,
+					Timeout:      5 * time.Second,
+				},
+			})
+			if tc.wantState == "" {
+				if err != nil {
+					t.Fatalf("validateExecSpec() error = %v, want nil", err)
+				}
+				return
+			}
+			var execErr *execCheckError
+			if !errors.As(err, &execErr) {
+				t.Fatalf("validateExecSpec() error = %v, want execCheckError", err)
+			}
+			if execErr.State != tc.wantState {
+				t.Fatalf("state = %q, want %q", execErr.State, tc.wantState)
+			}
+		})
+	}
+}
+
+func TestValidateExecSpecKeepsFirstLineAndPerfdata(t *testing.T) {
+	err := validateExecSpec(context.Background(), spec.Spec{
+		Exec: &spec.ExecSpec{
+			Name:    "disk",
+			Command: "sh",
+			Args:    []string{"-c", "printf 'DISK CRITICAL - /var is 95%% full | /var=95%%;80;90;0;100\\n/var/log: 3 GB\\n'; exit 2"},
+			Timeout: 5 * time.Second,
+		},
+	})
+	var execErr *execCheckError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("validateExecSpec() error = %v, want execCheckError", err)
+	}
+	if got := err.Error(); got != "CRITICAL: DISK CRITICAL - /var is 95% full" {
+		t.Fatalf("Error() = %q", got)
+	}
+	if len(execErr.Perfdata) != 1 || execErr.Perfdata[0].Label != "/var" || execErr.Perfdata[0].UOM != "%" {
+		t.Fatalf("perfdata = %+v", execErr.Perfdata)
+	}
+	details := execErr.Details()
+	if !strings.Contains(details, "/var/log: 3 GB") || !strings.Contains(details, "/var=95%;80;90;0;100") {
+		t.Fatalf("Details() = %q, want output and perfdata", details)
+	}
+}
+
+func TestValidateExecSpecEnvAndWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	err := validateExecSpec(context.Background(), spec.Spec{
+		Exec: &spec.ExecSpec{
+			Name:       "env",
+			Command:    "sh",
+			Args:       []string{"-c", `test "$CHECK_MODE" = strict && test "$(pwd)" = "$EXPECTED_DIR"`},
+			Env:        map[string]string{"CHECK_MODE": "strict", "EXPECTED_DIR": dir},
+			WorkingDir: dir,
+			Timeout:    5 * time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateExecSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateExecSpecTimeout(t *testing.T) {
+	err := validateExecSpec(context.Background(), spec.Spec{
+		Exec: &spec.ExecSpec{
+			Name:    "slow",
+			Command: "sleep",
+			Args:    []string{"5"},
+			Timeout: 100 * time.Millisecond,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("validateExecSpec() error = %v, want timeout", err)
+	}
+}
+
+func TestValidateExecSpecTimeoutKillsProcessGroup(t *testing.T) {
+	started := time.Now()
+	err := validateExecSpec(context.Background(), spec.Spec{
+		Exec: &spec.ExecSpec{
+			Name:    "forking",
+			Command: "sh",
+			Args:    []string{"-c", "sleep 30 & sleep 30"},
+			Timeout: 100 * time.Millisecond,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("validateExecSpec() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("validateExecSpec() took %s, want the background child killed", elapsed)
+	}
+}
+
+func TestValidateExecSpecDoesNotWaitForBackgroundChildren(t *testing.T) {
+	started := time.Now()
+	err := validateExecSpec(context.Background(), spec.Spec{
+		Exec: &spec.ExecSpec{
+			Name:    "backgrounding",
+			Command: "sh",
+			Args:    []string{"-c", "echo OK - started; sleep 30 &"},
+			Timeout: 10 * time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateExecSpec() error = %v, want nil", err)
+	}
+	if elapsed := time.Since(started); elapsed > execWaitDelay+3*time.Second {
+		t.Fatalf("validateExecSpec() took %s, want at most about %s", elapsed, execWaitDelay)
+	}
+}
+
+func TestParsePerfdata(t *testing.T) {
+	items := parsePerfdata(`'in use'=12.5MB;100;200;0; time=0.012s load1=0.5`)
+	if len(items) != 3 {
+		t.Fatalf("len(parsePerfdata) = %d, want 3 (%+v)", len(items), items)
+	}
+	if items[0].Label != "in use" || items[0].Value != "12.5" || items[0].UOM != "MB" || items[0].Crit != "200" {
+		t.Fatalf("items[0] = %+v", items[0])
+	}
+	if items[1].String() != "time=0.012s" {
+		t.Fatalf("items[1].String() = %q", items[1].String())
+	}
+}
+
+func TestFailureMailBodyIncludesDetails(t *testing.T) {
+	parsedSpec := spec.Spec{
+		Exec:       &spec.ExecSpec{Name: "disk"},
+		SourcePath: "/etc/eddie/exec.yaml",
+	}
+	body := failureMailBody(parsedSpec, &execCheckError{
+		State:   "CRITICAL",
+		Summary: "disk full",
+		Output:  "disk full\nline two",
+	}, nil)
+	if !strings.Contains(body, "reason: CRITICAL: disk full\r\n") {
+		t.Fatalf("body missing reason: %q", body)
+	}
+	if !strings.Contains(body, "output:\r\ndisk full\r\nline two\r\n") {
+		t.Fatalf("body missing output: %q", body)
+	}
+
+	plain := failureMailBody(parsedSpec, errors.New("boom"), nil)
+	if plain != "spec failed: exec:disk\r\nsource: /etc/eddie/exec.yaml\r\nreason: boom\r\n" {
+		t.Fatalf("plain body = %q", plain)
+	}
+}
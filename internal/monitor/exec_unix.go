@@ -0,0 +1,18 @@
+//go:build unix
+
+package monitor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startExecProcessGroup makes the plugin the leader of a new process group.
+func startExecProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killExecProcessGroup kills the plugin and every process in its group.
+func killExecProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+type matchedFile struct {
+	path string
+	info fs.FileInfo
+}
+
+func validateFileSpec(_ context.Context, parsedSpec spec.Spec) error {
+	fileSpec := parsedSpec.File
+	if fileSpec == nil {
+		return fmt.Errorf("missing file spec")
+	}
+
+	pattern := expandTimeTemplate(strings.TrimSpace(fileSpec.Path))
+	files, err := globRegularFiles(pattern)
+	if err != nil {
+		return err
+	}
+
+	expect := fileSpec.Expect
+	if expect.Absent {
+		if len(files) > 0 {
+			return fmt.Errorf("found %d file(s) matching %q, want none (first: %s)", len(files), pattern, files[0].path)
+		}
+		return nil
+	}
+
+	count := len(files)
+	if expect.CountGT != nil && !(count > *expect.CountGT) {
+		return fmt.Errorf("unexpected file count: got %d, want > %d", count, *expect.CountGT)
+	}
+	if expect.CountGTE != nil && !(count >= *expect.CountGTE) {
+		return fmt.Errorf("unexpected file count: got %d, want >= %d", count, *expect.CountGTE)
+	}
+	if expect.CountEQ != nil && count != *expect.CountEQ {
+		return fmt.Errorf("unexpected file count: got %d, want == %d", count, *expect.CountEQ)
+	}
+	if count == 0 {
+		if expect.CountGT != nil || expect.CountGTE != nil || expect.CountEQ != nil {
+			return nil
+		}
+		return fmt.Errorf("no file matches %q", pattern)
+	}
+
+	newest := files[0]
+	for _, file := range files[1:] {
+		if file.info.ModTime().After(newest.info.ModTime()) {
+			newest = file
+		}
+	}
+	return checkFileExpect(newest, expect)
+}
+
+// globRegularFiles returns the regular files (following symlinks) that match
+// the doublestar pattern.
+func globRegularFiles(pattern string) ([]matchedFile, error) {
+	paths, err := doublestar.FilepathGlob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("resolve glob %q: %w", pattern, err)
+	}
+	files := make([]matchedFile, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, matchedFile{path: path, info: info})
+	}
+	return files, nil
+}
+
+func checkFileExpect(file matchedFile, expect spec.FileExpect) error {
+	size := file.info.Size()
+	if expect.MinSize != nil && size < *expect.MinSize {
+		return fmt.Errorf("file %s has %d bytes, want >= %d", file.path, size, *expect.MinSize)
+	}
+	if expect.MaxSize != nil && size > *expect.MaxSize {
+		return fmt.Errorf("file %s has %d bytes, want <= %d", file.path, size, *expect.MaxSize)
+	}
+	if expect.MaxAge > 0 {
+		age := time.Since(file.info.ModTime())
+		if age > expect.MaxAge {
+			return fmt.Errorf("file %s was modified %s ago, want <= %s", file.path, age.Round(time.Second), expect.MaxAge)
+		}
+	}
+
+	if expect.Contains != "" || expect.Regex != "" {
+		content, err := os.ReadFile(file.path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file.path, err)
+		}
+		if expect.Contains != "" && !strings.Contains(string(content), expect.Contains) {
+			return fmt.Errorf("file %s does not contain %q", file.path, expect.Contains)
+		}
+		if expect.Regex != "" {
+			matcher, err := regexp.Compile(expect.Regex)
+			if err != nil {
+				return fmt.Errorf("compile regex: %w", err)
+			}
+			if !matcher.Match(content) {
+				return fmt.Errorf("file %s does not match %q", file.path, expect.Regex)
+			}
+		}
+	}
+
+	if want := strings.ToLower(strings.TrimSpace(expect.SHA256)); want != "" {
+		got, err := fileSHA256(file.path)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("file %s has sha256 %s, want %s", file.path, got, want)
+		}
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	handle, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", path, err)
+	}
+	defer handle.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, handle); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
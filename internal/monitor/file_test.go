@@ -0,0 +1,176 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func writeTestFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+}
+
+func TestValidateFileSpecNewestMatchSHA256Mismatch(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTestFile(t, filepath.Join(dir, "exports", "a", "orders-old.csv"), "id,total\n", now.Add(-48*time.Hour))
+	writeTestFile(t, filepath.Join(dir, "exports", "b", "orders-new.csv"), "id,total\n1,9.99\n", now.Add(-time.Minute))
+
+	minSize := int64(10)
+	count := 2
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{
+			Name: "exports",
+			Path: filepath.Join(dir, "exports", "**", "orders-*.csv"),
+			Expect: spec.FileExpect{
+				CountEQ:  &count,
+				MinSize:  &minSize,
+				MaxAge:   time.Hour,
+				Contains: "id,total",
+				Regex:    `(?m)^1,\d+\.\d{2}$`,
+				SHA256:   "b1be6ac2f1fe4fc4ca0da83b4e76a3eadca76dbcd95c2a7ce8bb1fa0f8dc3fba",
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "has sha256") {
+		t.Fatalf("validateFileSpec() error = %v, want sha256 mismatch", err)
+	}
+	if !strings.Contains(err.Error(), "orders-new.csv") {
+		t.Fatalf("validateFileSpec() error = %v, want newest file", err)
+	}
+}
+
+func TestValidateFileSpecNewestMatch(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTestFile(t, filepath.Join(dir, "exports", "a", "orders-old.csv"), "id,total\n", now.Add(-48*time.Hour))
+	writeTestFile(t, filepath.Join(dir, "exports", "b", "orders-new.csv"), "id,total\n1,9.99\n", now.Add(-time.Minute))
+	sum, err := fileSHA256(filepath.Join(dir, "exports", "b", "orders-new.csv"))
+	if err != nil {
+		t.Fatalf("fileSHA256() error = %v", err)
+	}
+
+	minSize := int64(10)
+	count := 2
+	err = validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{
+			Name: "exports",
+			Path: filepath.Join(dir, "exports", "**", "orders-*.csv"),
+			Expect: spec.FileExpect{
+				CountEQ:  &count,
+				MinSize:  &minSize,
+				MaxAge:   time.Hour,
+				Contains: "id,total",
+				Regex:    `(?m)^1,\d+\.\d{2}$`,
+				SHA256:   strings.ToUpper(sum),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateFileSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateFileSpecMissing(t *testing.T) {
+	dir := t.TempDir()
+
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "sitemap", Path: filepath.Join(dir, "missing.xml")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no file matches") {
+		t.Fatalf("validateFileSpec() error = %v, want no match", err)
+	}
+}
+
+func TestValidateFileSpecAbsent(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "sitemap.xml"), "<urlset></urlset>", time.Now())
+
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "lock", Path: filepath.Join(dir, "*.lock"), Expect: spec.FileExpect{Absent: true}},
+	})
+	if err != nil {
+		t.Fatalf("validateFileSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateFileSpecAbsentButPresent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sitemap.xml")
+	writeTestFile(t, path, "<urlset></urlset>", time.Now())
+
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "sitemap", Path: path, Expect: spec.FileExpect{Absent: true}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "want none") {
+		t.Fatalf("validateFileSpec() error = %v, want present file failure", err)
+	}
+}
+
+func TestValidateFileSpecStale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sitemap.xml")
+	writeTestFile(t, path, "<urlset></urlset>", time.Now().Add(-2*time.Hour))
+
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "sitemap", Path: path, Expect: spec.FileExpect{MaxAge: time.Hour}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Fatalf("validateFileSpec() error = %v, want stale file failure", err)
+	}
+}
+
+func TestValidateFileSpecTooBig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sitemap.xml")
+	writeTestFile(t, path, "<urlset></urlset>", time.Now())
+
+	maxSize := int64(5)
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "sitemap", Path: path, Expect: spec.FileExpect{MaxSize: &maxSize}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "want <= 5") {
+		t.Fatalf("validateFileSpec() error = %v, want size failure", err)
+	}
+}
+
+func TestValidateFileSpecContentMismatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sitemap.xml")
+	writeTestFile(t, path, "<urlset></urlset>", time.Now())
+
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "sitemap", Path: path, Expect: spec.FileExpect{Contains: "<url>"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not contain") {
+		t.Fatalf("validateFileSpec() error = %v, want content failure", err)
+	}
+}
+
+func TestValidateFileSpecTimeTemplate(t *testing.T) {
+	dir := t.TempDir()
+	hour := time.Now().UTC().Format("2006-01-02-15")
+	writeTestFile(t, filepath.Join(dir, "backup-"+hour+".tar.gz"), "archive", time.Now())
+
+	err := validateFileSpec(context.Background(), spec.Spec{
+		File: &spec.FileSpec{Name: "backup", Path: filepath.Join(dir, "backup-{utc_hour}.tar.gz")},
+	})
+	if err != nil {
+		t.Fatalf("validateFileSpec() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,283 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcReflectionMethods lists the reflection services to try, newest first.
+// Both versions share the same wire format.
+var grpcReflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+func validateGRPCSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	grpcSpec := parsedSpec.GRPC
+	if grpcSpec == nil {
+		return fmt.Errorf("missing grpc spec")
+	}
+
+	target := strings.TrimSpace(grpcSpec.Target)
+	if target == "" {
+		return fmt.Errorf("grpc.target is required")
+	}
+
+	timeout := grpcSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if len(grpcSpec.Metadata) > 0 {
+		callCtx = metadata.NewOutgoingContext(callCtx, metadata.New(grpcSpec.Metadata))
+	}
+
+	creds := insecure.NewCredentials()
+	if !grpcSpec.Plaintext {
+		tlsConfig, err := buildClientTLSConfig(grpcSpec.TLS, grpcSpec.InsecureSkipTLS)
+		if err != nil {
+			return err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("create grpc client: %w", err)
+	}
+	defer conn.Close()
+
+	if grpcSpec.Health == nil || *grpcSpec.Health {
+		if err := checkGRPCHealth(callCtx, conn, grpcSpec.Service); err != nil {
+			return err
+		}
+	}
+
+	if grpcSpec.Call != nil {
+		if err := checkGRPCCall(callCtx, conn, *grpcSpec.Call); err != nil {
+			return fmt.Errorf("call %s: %w", strings.TrimPrefix(strings.TrimSpace(grpcSpec.Call.Method), "/"), err)
+		}
+	}
+
+	return nil
+}
+
+func checkGRPCHealth(ctx context.Context, conn *grpc.ClientConn, service string) error {
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("health check: %w", err)
+	}
+	if response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		if service == "" {
+			return fmt.Errorf("health status %s, want SERVING", response.GetStatus())
+		}
+		return fmt.Errorf("health status of %q %s, want SERVING", service, response.GetStatus())
+	}
+	return nil
+}
+
+func checkGRPCCall(ctx context.Context, conn *grpc.ClientConn, call spec.GRPCCall) error {
+	fullMethod := strings.TrimPrefix(strings.TrimSpace(call.Method), "/")
+	serviceName, methodName, ok := strings.Cut(fullMethod, "/")
+	if !ok || serviceName == "" || methodName == "" {
+		return fmt.Errorf("method must be in the form package.Service/Method")
+	}
+
+	reflection := &grpcReflectionClient{conn: conn}
+	method, err := reflection.resolveMethod(ctx, serviceName, methodName)
+	if err != nil {
+		return err
+	}
+
+	requestJSON := []byte("{}")
+	if call.Request != nil {
+		requestJSON, err = json.Marshal(call.Request)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+	request := dynamicpb.NewMessage(method.Input())
+	if err := protojson.Unmarshal(requestJSON, request); err != nil {
+		return fmt.Errorf("encode request as %s: %w", method.Input().FullName(), err)
+	}
+
+	response := dynamicpb.NewMessage(method.Output())
+	if err := conn.Invoke(ctx, "/"+fullMethod, request, response); err != nil {
+		return err
+	}
+
+	rendered, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("render response: %w", err)
+	}
+	// protojson output is deliberately unstable in whitespace.
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, rendered); err != nil {
+		return fmt.Errorf("render response: %w", err)
+	}
+	responseJSON := compact.String()
+
+	if err := checkResponseExpect(call.Expect.Response, responseJSON); err != nil {
+		return err
+	}
+	return checkJSONPathExpect(responseJSON, call.Expect.JSONPath)
+}
+
+// grpcReflectionClient resolves method descriptors via server reflection.
+type grpcReflectionClient struct {
+	conn   *grpc.ClientConn
+	method string
+}
+
+func (c *grpcReflectionClient) resolveMethod(ctx context.Context, serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
+	response, err := c.request(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolve service %q: %w", serviceName, err)
+	}
+
+	fileProtos := make(map[string]*descriptorpb.FileDescriptorProto)
+	pending := make([]*descriptorpb.FileDescriptorProto, 0)
+	addFiles := func(response *reflectionpb.ServerReflectionResponse) error {
+		for _, raw := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fileProto := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(raw, fileProto); err != nil {
+				return fmt.Errorf("decode file descriptor: %w", err)
+			}
+			if _, exists := fileProtos[fileProto.GetName()]; exists {
+				continue
+			}
+			fileProtos[fileProto.GetName()] = fileProto
+			pending = append(pending, fileProto)
+		}
+		return nil
+	}
+	if err := addFiles(response); err != nil {
+		return nil, err
+	}
+
+	for len(pending) > 0 {
+		fileProto := pending[0]
+		pending = pending[1:]
+		for _, dependency := range fileProto.GetDependency() {
+			if _, exists := fileProtos[dependency]; exists {
+				continue
+			}
+			response, err := c.request(ctx, &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+			})
+			if err == nil {
+				err = addFiles(response)
+			}
+			if err != nil {
+				fallback, lookupErr := protoregistry.GlobalFiles.FindFileByPath(dependency)
+				if lookupErr != nil {
+					return nil, fmt.Errorf("resolve dependency %q: %w", dependency, err)
+				}
+				// The fallback file's own imports still need resolving.
+				fallbackProto := protodesc.ToFileDescriptorProto(fallback)
+				fileProtos[dependency] = fallbackProto
+				pending = append(pending, fallbackProto)
+			}
+		}
+	}
+
+	fileSet := &descriptorpb.FileDescriptorSet{File: make([]*descriptorpb.FileDescriptorProto, 0, len(fileProtos))}
+	for _, fileProto := range fileProtos {
+		fileSet.File = append(fileSet.File, fileProto)
+	}
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, fmt.Errorf("build descriptors for %q: %w", serviceName, err)
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("find service %q: %w", serviceName, err)
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("service %q has no method %q", serviceName, methodName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("method %q is streaming; only unary methods are supported", methodName)
+	}
+	return method, nil
+}
+
+func (c *grpcReflectionClient) request(ctx context.Context, request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	methods := grpcReflectionMethods
+	if c.method != "" {
+		methods = []string{c.method}
+	}
+
+	var lastErr error
+	for _, method := range methods {
+		response, err := c.roundTrip(ctx, method, request)
+		if status.Code(err) == codes.Unimplemented {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.method = method
+		return response, nil
+	}
+	return nil, fmt.Errorf("server reflection is not available: %w", lastErr)
+}
+
+func (c *grpcReflectionClient) roundTrip(ctx context.Context, method string, request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.conn.NewStream(streamCtx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, method)
+	if err != nil {
+		return nil, err
+	}
+	// On io.EOF the actual error is reported by RecvMsg.
+	if err := stream.SendMsg(request); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	response := new(reflectionpb.ServerReflectionResponse)
+	if err := stream.RecvMsg(response); err != nil {
+		return nil, err
+	}
+	if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+		return nil, status.Error(codes.Code(errorResponse.GetErrorCode()), errorResponse.GetErrorMessage())
+	}
+	return response, nil
+}
@@ -0,0 +1,257 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/apipb"
+)
+
+func startGRPCServer(t *testing.T) (string, *health.Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String(), healthServer
+}
+
+func TestValidateGRPCSpecHealthServing(t *testing.T) {
+	target, _ := startGRPCServer(t)
+
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-health",
+			Target:    target,
+			Plaintext: true,
+			Timeout:   5 * time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateGRPCSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateGRPCSpecHealthNotServing(t *testing.T) {
+	target, healthServer := startGRPCServer(t)
+	healthServer.SetServingStatus("billing.Billing", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-health",
+			Target:    target,
+			Plaintext: true,
+			Service:   "billing.Billing",
+			Timeout:   5 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "NOT_SERVING") {
+		t.Fatalf("validateGRPCSpec() error = %v, want NOT_SERVING", err)
+	}
+}
+
+func TestValidateGRPCSpecReflectionCall(t *testing.T) {
+	target, _ := startGRPCServer(t)
+
+	disabled := false
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-call",
+			Target:    target,
+			Plaintext: true,
+			Health:    &disabled,
+			Timeout:   5 * time.Second,
+			Call: &spec.GRPCCall{
+				Method:  "grpc.health.v1.Health/Check",
+				Request: map[string]any{"service": ""},
+				Expect: spec.GRPCCallExpect{
+					Response: spec.ResponseExpect{Contains: `"status":"SERVING"`},
+					JSONPath: map[string]any{"status": "SERVING"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateGRPCSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateGRPCSpecReflectionCallJSONPathMismatch(t *testing.T) {
+	target, _ := startGRPCServer(t)
+
+	disabled := false
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-call",
+			Target:    target,
+			Plaintext: true,
+			Health:    &disabled,
+			Timeout:   5 * time.Second,
+			Call: &spec.GRPCCall{
+				Method:  "grpc.health.v1.Health/Check",
+				Request: map[string]any{"service": ""},
+				Expect: spec.GRPCCallExpect{
+					JSONPath: map[string]any{"status": "NOT_SERVING"},
+				},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `json_path "status"`) {
+		t.Fatalf("validateGRPCSpec() error = %v, want json_path mismatch", err)
+	}
+}
+
+func TestValidateGRPCSpecReflectionUnknownMethod(t *testing.T) {
+	target, _ := startGRPCServer(t)
+
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-call",
+			Target:    target,
+			Plaintext: true,
+			Timeout:   5 * time.Second,
+			Call:      &spec.GRPCCall{Method: "grpc.health.v1.Health/Missing"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "has no method") {
+		t.Fatalf("validateGRPCSpec() error = %v, want unknown method", err)
+	}
+}
+
+func TestValidateGRPCSpecReflectionRejectsStreamingMethod(t *testing.T) {
+	target, _ := startGRPCServer(t)
+
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-call",
+			Target:    target,
+			Plaintext: true,
+			Timeout:   5 * time.Second,
+			Call:      &spec.GRPCCall{Method: "grpc.health.v1.Health/Watch"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "only unary methods") {
+		t.Fatalf("validateGRPCSpec() error = %v, want streaming rejection", err)
+	}
+}
+
+func TestValidateGRPCSpecReflectionUnknownService(t *testing.T) {
+	target, _ := startGRPCServer(t)
+
+	err := validateGRPCSpec(context.Background(), spec.Spec{
+		GRPC: &spec.GRPCSpec{
+			Name:      "grpc-call",
+			Target:    target,
+			Plaintext: true,
+			Timeout:   5 * time.Second,
+			Call:      &spec.GRPCCall{Method: "missing.Service/Call"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "resolve service") {
+		t.Fatalf("validateGRPCSpec() error = %v, want unresolved service", err)
+	}
+}
+
+// startPartialReflectionServer serves reflection for a single file that
+// imports google/protobuf/api.proto and answers every other request with
+// NotFound, so dependencies must come from the local registry.
+func startPartialReflectionServer(t *testing.T) string {
+	t.Helper()
+
+	catalog, err := proto.Marshal(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("eddie/catalog.proto"),
+		Package:    proto.String("eddie.test"),
+		Dependency: []string{"google/protobuf/api.proto"},
+		Syntax:     proto.String("proto3"),
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Catalog"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Describe"),
+				InputType:  proto.String(".google.protobuf.Api"),
+				OutputType: proto.String(".google.protobuf.Api"),
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "grpc.reflection.v1.ServerReflection",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "ServerReflectionInfo",
+			ServerStreams: true,
+			ClientStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				request := new(reflectionpb.ServerReflectionRequest)
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				response := &reflectionpb.ServerReflectionResponse{
+					MessageResponse: &reflectionpb.ServerReflectionResponse_ErrorResponse{
+						ErrorResponse: &reflectionpb.ErrorResponse{ErrorCode: int32(codes.NotFound), ErrorMessage: "not found"},
+					},
+				}
+				if request.GetFileContainingSymbol() == "eddie.test.Catalog" {
+					response.MessageResponse = &reflectionpb.ServerReflectionResponse_FileDescriptorResponse{
+						FileDescriptorResponse: &reflectionpb.FileDescriptorResponse{FileDescriptorProto: [][]byte{catalog}},
+					}
+				}
+				return stream.SendMsg(response)
+			},
+		}},
+	}, struct{}{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestGRPCReflectionResolvesImportsOfRegistryFallbackFiles(t *testing.T) {
+	target := startPartialReflectionServer(t)
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := &grpcReflectionClient{conn: conn}
+	method, err := client.resolveMethod(ctx, "eddie.test.Catalog", "Describe")
+	if err != nil {
+		t.Fatalf("resolveMethod() error = %v, want nil", err)
+	}
+	if got := method.Input().FullName(); got != "google.protobuf.Api" {
+		t.Fatalf("method input = %q, want google.protobuf.Api", got)
+	}
+}
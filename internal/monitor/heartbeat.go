@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/fabiant7t/eddie/internal/state"
+)
+
+// RecordHeartbeat stores an inbound check-in for the heartbeat spec owning
+// token. Supported signals are "success", "start" and "fail". It reports
+// false when the token or signal is unknown.
+func (r *Runner) RecordHeartbeat(token, signal string) bool {
+	parsedSpec, ok := r.heartbeatTokens[token]
+	if !ok || token == "" {
+		return false
+	}
+
+	heartbeatSignal := state.HeartbeatSignal(signal)
+	switch heartbeatSignal {
+	case state.HeartbeatSuccess, state.HeartbeatStart, state.HeartbeatFailure:
+	default:
+		return false
+	}
+
+	r.heartbeatStore.Record(parsedSpec.ID(), heartbeatSignal, time.Now())
+	slog.Debug("heartbeat_received",
+		"name", parsedSpec.Name(),
+		"type", parsedSpec.Kind(),
+		"signal", signal,
+	)
+	return true
+}
+
+func (r *Runner) validateHeartbeatSpec(parsedSpec spec.Spec, now time.Time) error {
+	heartbeat := parsedSpec.Heartbeat
+	if heartbeat == nil {
+		return fmt.Errorf("missing heartbeat spec")
+	}
+	if heartbeat.Period <= 0 {
+		return fmt.Errorf("heartbeat.period must be positive")
+	}
+
+	heartbeatState, _ := r.heartbeatStore.Get(parsedSpec.ID())
+	return evaluateHeartbeat(heartbeatState, heartbeat.Period, heartbeat.Grace, r.startedAt, now)
+}
+
+// evaluateHeartbeat fails when the latest check-in reported a failure, when a
+// started job did not finish within grace, or when no success arrived within
+// period+grace (measured from since when there was none yet).
+func evaluateHeartbeat(heartbeatState state.HeartbeatState, period, grace time.Duration, since, now time.Time) error {
+	lastSuccess := heartbeatState.LastSuccessAt
+	lastStart := heartbeatState.LastStartAt
+	lastFailure := heartbeatState.LastFailureAt
+
+	if !lastFailure.IsZero() && !lastFailure.Before(lastSuccess) && !lastFailure.Before(lastStart) {
+		return fmt.Errorf("job reported failure at %s", lastFailure.UTC().Format(time.RFC3339))
+	}
+	if grace > 0 && !lastStart.IsZero() && lastStart.After(lastSuccess) && now.Sub(lastStart) > grace {
+		return fmt.Errorf("job started at %s but did not finish within grace %s", lastStart.UTC().Format(time.RFC3339), grace)
+	}
+
+	if lastSuccess.IsZero() {
+		if now.Sub(since) > period+grace {
+			return fmt.Errorf("no ping received since %s (period %s, grace %s)", since.UTC().Format(time.RFC3339), period, grace)
+		}
+		return nil
+	}
+	if now.Sub(lastSuccess) > period+grace {
+		return fmt.Errorf("last ping at %s is overdue (period %s, grace %s)", lastSuccess.UTC().Format(time.RFC3339), period, grace)
+	}
+	return nil
+}
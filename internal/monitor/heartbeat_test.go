@@ -0,0 +1,127 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/fabiant7t/eddie/internal/state"
+)
+
+func TestEvaluateHeartbeat(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	period := time.Hour
+	grace := 10 * time.Minute
+
+	tests := []struct {
+		name    string
+		state   state.HeartbeatState
+		now     time.Time
+		wantErr string
+	}{
+		{
+			name: "waiting for first ping",
+			now:  since.Add(65 * time.Minute),
+		},
+		{
+			name:    "first ping overdue",
+			now:     since.Add(71 * time.Minute),
+			wantErr: "no ping received",
+		},
+		{
+			name:  "recent success",
+			state: state.HeartbeatState{LastSuccessAt: since.Add(2 * time.Hour)},
+			now:   since.Add(3 * time.Hour),
+		},
+		{
+			name:    "success overdue",
+			state:   state.HeartbeatState{LastSuccessAt: since.Add(2 * time.Hour)},
+			now:     since.Add(3*time.Hour + 11*time.Minute),
+			wantErr: "overdue",
+		},
+		{
+			name: "reported failure",
+			state: state.HeartbeatState{
+				LastSuccessAt: since.Add(time.Hour),
+				LastFailureAt: since.Add(2 * time.Hour),
+			},
+			now:     since.Add(2 * time.Hour),
+			wantErr: "reported failure",
+		},
+		{
+			name: "success after failure",
+			state: state.HeartbeatState{
+				LastFailureAt: since.Add(time.Hour),
+				LastSuccessAt: since.Add(2 * time.Hour),
+			},
+			now: since.Add(2 * time.Hour),
+		},
+		{
+			name: "started job within grace",
+			state: state.HeartbeatState{
+				LastSuccessAt: since.Add(time.Hour),
+				LastStartAt:   since.Add(2 * time.Hour),
+			},
+			now: since.Add(2*time.Hour + 5*time.Minute),
+		},
+		{
+			name: "started job exceeded grace",
+			state: state.HeartbeatState{
+				LastSuccessAt: since.Add(time.Hour),
+				LastStartAt:   since.Add(90 * time.Minute),
+			},
+			now:     since.Add(2 * time.Hour),
+			wantErr: "did not finish within grace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evaluateHeartbeat(tt.state, period, grace, since, tt.now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("evaluateHeartbeat() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("evaluateHeartbeat() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunnerRecordHeartbeat(t *testing.T) {
+	heartbeatSpec := spec.Spec{
+		Heartbeat: &spec.HeartbeatSpec{
+			Name:   "nightly-backup",
+			Token:  "nightly-backup-token",
+			Period: time.Hour,
+		},
+		SourcePath: "/tmp/heartbeat.yaml",
+	}
+	runner := NewRunner([]spec.Spec{heartbeatSpec}, time.Minute, 0, nil, nil, nil, "")
+
+	if runner.RecordHeartbeat("unknown-token-value", "success") {
+		t.Fatal("RecordHeartbeat() = true for unknown token, want false")
+	}
+	if runner.RecordHeartbeat("nightly-backup-token", "bogus") {
+		t.Fatal("RecordHeartbeat() = true for unknown signal, want false")
+	}
+	if !runner.RecordHeartbeat("nightly-backup-token", "fail") {
+		t.Fatal("RecordHeartbeat() = false, want true")
+	}
+
+	err := runner.validateHeartbeatSpec(heartbeatSpec, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "reported failure") {
+		t.Fatalf("validateHeartbeatSpec() error = %v, want reported failure", err)
+	}
+
+	if !runner.RecordHeartbeat("nightly-backup-token", "success") {
+		t.Fatal("RecordHeartbeat() = false, want true")
+	}
+	if err := runner.validateHeartbeatSpec(heartbeatSpec, time.Now()); err != nil {
+		t.Fatalf("validateHeartbeatSpec() error = %v, want nil", err)
+	}
+}
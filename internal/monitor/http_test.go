@@ -2,11 +2,21 @@ package monitor
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/fabiant7t/eddie/internal/state"
 )
 
 func TestValidateHTTPSpecSupportsHostHeaderAndLocationContains(t *testing.T) {
@@ -81,6 +91,213 @@ func TestValidateHTTPSpecInsecureSkipVerify(t *testing.T) {
 	}
 }
 
+func TestValidateHTTPSpecSendsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType := strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0]
+		switch mediaType {
+		case "application/x-www-form-urlencoded":
+			_ = r.ParseForm()
+			fmt.Fprintf(w, "%s %s user=%s", r.Method, mediaType, r.PostForm.Get("user"))
+		case "multipart/form-data":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fields := make([]string, 0)
+			for name := range r.MultipartForm.Value {
+				fields = append(fields, name+"="+r.MultipartForm.Value[name][0])
+			}
+			for name, headers := range r.MultipartForm.File {
+				file, _ := headers[0].Open()
+				content, _ := io.ReadAll(file)
+				fields = append(fields, fmt.Sprintf("%s=%s(%s):%s", name, headers[0].Filename, headers[0].Header.Get("Content-Type"), content))
+			}
+			sort.Strings(fields)
+			fmt.Fprintf(w, "%s %s %s", r.Method, mediaType, strings.Join(fields, " "))
+		default:
+			content, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("Content-Type"), content)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	payloadPath := filepath.Join(dir, "payload.json")
+	writeTestFile(t, payloadPath, `{"query":"{ health }"}`, time.Now())
+	reportPath := filepath.Join(dir, "report.csv")
+	writeTestFile(t, reportPath, "a,b", time.Now())
+
+	tests := []struct {
+		name   string
+		method string
+		body   *spec.HTTPBody
+		want   string
+	}{
+		{
+			name: "raw defaults to post",
+			body: &spec.HTTPBody{Raw: "ping"},
+			want: "POST text/plain; charset=utf-8 ping",
+		},
+		{
+			name:   "json from yaml values",
+			method: http.MethodPut,
+			body:   &spec.HTTPBody{JSON: map[string]any{"user": "eddie", "tags": []any{"a", 1}}},
+			want:   `PUT application/json {"tags":["a",1],"user":"eddie"}`,
+		},
+		{
+			name: "form",
+			body: &spec.HTTPBody{Form: map[string]string{"user": "eddie"}},
+			want: "POST application/x-www-form-urlencoded user=eddie",
+		},
+		{
+			name: "multipart with file",
+			body: &spec.HTTPBody{Multipart: &spec.HTTPMultipart{
+				Fields: map[string]string{"kind": "daily"},
+				Files:  []spec.HTTPMultipartFile{{Field: "upload", Path: reportPath, ContentType: "text/csv"}},
+			}},
+			want: "POST multipart/form-data kind=daily upload=report.csv(text/csv):a,b",
+		},
+		{
+			name: "file with content type",
+			body: &spec.HTTPBody{File: payloadPath, ContentType: "application/graphql+json"},
+			want: `POST application/graphql+json {"query":"{ health }"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHTTPSpec(context.Background(), spec.Spec{
+				HTTP: &spec.HTTPSpec{
+					Name:   "body",
+					Method: tt.method,
+					URL:    server.URL,
+					Body:   tt.body,
+					Expect: spec.HTTPExpect{Body: spec.HTTPExpectBody{Exact: tt.want}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPSpecFailsOnMissingBodyFile(t *testing.T) {
+	err := validateHTTPSpec(context.Background(), spec.Spec{
+		HTTP: &spec.HTTPSpec{
+			Name: "missing-body",
+			URL:  "http://127.0.0.1:1",
+			Body: &spec.HTTPBody{File: filepath.Join(t.TempDir(), "missing.json")},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "read body file") {
+		t.Fatalf("validateHTTPSpec() error = %v, want body file error", err)
+	}
+}
+
+func TestValidateHTTPSpecTimingLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		expect  spec.HTTPTimingExpect
+		wantErr string
+	}{
+		{name: "within limits", expect: spec.HTTPTimingExpect{MaxDuration: 5 * time.Second, MaxPhase: spec.HTTPPhaseLimits{Connect: time.Second, TTFB: 5 * time.Second}}},
+		{name: "max duration", expect: spec.HTTPTimingExpect{MaxDuration: 10 * time.Millisecond}, wantErr: "exceeds max_duration 10ms"},
+		{name: "ttfb", expect: spec.HTTPTimingExpect{MaxPhase: spec.HTTPPhaseLimits{TTFB: 10 * time.Millisecond}}, wantErr: "exceeds max_phase.ttfb 10ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, recorder := withTimingRecorder(context.Background())
+			err := validateHTTPSpec(ctx, spec.Spec{
+				HTTP: &spec.HTTPSpec{
+					Name:   "slow",
+					URL:    server.URL,
+					Expect: spec.HTTPExpect{Code: http.StatusOK, HTTPTimingExpect: tt.expect},
+				},
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validateHTTPSpec() error = %v, want containing %q", err, tt.wantErr)
+			}
+
+			timings := recorder.Timings()
+			if len(timings) != 1 {
+				t.Fatalf("len(timings) = %d, want 1", len(timings))
+			}
+			if timings[0].TTFB < 50*time.Millisecond || timings[0].Total < timings[0].TTFB || timings[0].Connect <= 0 {
+				t.Fatalf("unexpected timing %+v", timings[0])
+			}
+		})
+	}
+}
+
+func TestValidateHTTPSpecMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.Server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.Pool,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		tls      spec.ClientTLS
+		insecure bool
+		wantErr  string
+	}{
+		{name: "client certificate and private ca", tls: spec.ClientTLS{CAFile: pki.CAFile, ClientCert: pki.ClientCertFile, ClientKey: pki.ClientKeyFile}},
+		{name: "tls 1.3 only", tls: spec.ClientTLS{CAFile: pki.CAFile, ClientCert: pki.ClientCertFile, ClientKey: pki.ClientKeyFile, MinVersion: "1.3"}},
+		{name: "untrusted server", tls: spec.ClientTLS{ClientCert: pki.ClientCertFile, ClientKey: pki.ClientKeyFile}, wantErr: "server certificate untrusted"},
+		{name: "server name mismatch", tls: spec.ClientTLS{CAFile: pki.CAFile, ClientCert: pki.ClientCertFile, ClientKey: pki.ClientKeyFile, ServerName: "api.internal"}, wantErr: "server certificate untrusted"},
+		{name: "missing client certificate", tls: spec.ClientTLS{CAFile: pki.CAFile}, wantErr: "client certificate rejected by server"},
+		{name: "insecure without client certificate", insecure: true, wantErr: "client certificate rejected by server"},
+		{name: "bad client certificate", tls: spec.ClientTLS{CAFile: pki.CAFile, ClientCert: pki.CAFile, ClientKey: pki.ClientKeyFile}, wantErr: "bad client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHTTPSpec(context.Background(), spec.Spec{
+				HTTP: &spec.HTTPSpec{
+					Name:            "mtls",
+					URL:             server.URL,
+					TLS:             tt.tls,
+					InsecureSkipTLS: tt.insecure,
+					Expect:          spec.HTTPExpect{Body: spec.HTTPExpectBody{Exact: "eddie"}},
+				},
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validateHTTPSpec() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFailureMailBodyIncludesTimings(t *testing.T) {
+	body := failureMailBody(
+		spec.Spec{HTTP: &spec.HTTPSpec{Name: "api"}, SourcePath: "/etc/eddie/api.yaml"},
+		errors.New("request took 2.5s, exceeds max_duration 2s"),
+		[]state.RequestTiming{{DNS: 3 * time.Millisecond, Connect: 12 * time.Millisecond, TTFB: 2400 * time.Millisecond, Total: 2500 * time.Millisecond}},
+	)
+	if !strings.Contains(body, "timings:\r\n  dns 3ms, connect 12ms, ttfb 2.4s, total 2.5s\r\n") {
+		t.Fatalf("body missing timings: %q", body)
+	}
+}
+
 func TestContainsStatusCode(t *testing.T) {
 	if containsStatusCode([]int{301, 302, 307, 308}, 302) != true {
 		t.Fatalf("containsStatusCode() = false, want true")
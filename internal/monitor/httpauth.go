@@ -0,0 +1,228 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// oauth2RefreshMargin renews cached tokens this long before they expire, so
+// a token does not run out while a check is using it.
+const oauth2RefreshMargin = 30 * time.Second
+
+type oauth2TokenCacheKey struct{}
+
+// oauth2TokenCache keeps client credentials tokens across check cycles.
+type oauth2TokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*oauth2TokenEntry
+}
+
+type oauth2TokenEntry struct {
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func newOAuth2TokenCache() *oauth2TokenCache {
+	return &oauth2TokenCache{entries: make(map[string]*oauth2TokenEntry)}
+}
+
+// withOAuth2TokenCache returns a context whose HTTP checks reuse tokens from cache.
+func withOAuth2TokenCache(ctx context.Context, cache *oauth2TokenCache) context.Context {
+	if cache == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2TokenCacheKey{}, cache)
+}
+
+func oauth2TokenCacheFrom(ctx context.Context) *oauth2TokenCache {
+	cache, ok := ctx.Value(oauth2TokenCacheKey{}).(*oauth2TokenCache)
+	if !ok {
+		// Without a runner-owned cache every check fetches a fresh token.
+		return newOAuth2TokenCache()
+	}
+	return cache
+}
+
+func (c *oauth2TokenCache) entry(key string) *oauth2TokenEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &oauth2TokenEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// token returns a cached token or requests a new one from the token endpoint.
+func (c *oauth2TokenCache) token(ctx context.Context, client *http.Client, oauth2 spec.HTTPOAuth2, clientSecret string) (string, error) {
+	entry := c.entry(oauth2CacheKey(oauth2, clientSecret))
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.accessToken != "" && (entry.expiresAt.IsZero() || time.Now().Add(oauth2RefreshMargin).Before(entry.expiresAt)) {
+		return entry.accessToken, nil
+	}
+	accessToken, expiresIn, err := requestOAuth2Token(ctx, client, oauth2, clientSecret)
+	if err != nil {
+		return "", err
+	}
+	entry.accessToken = accessToken
+	entry.expiresAt = time.Time{}
+	if expiresIn > 0 {
+		entry.expiresAt = time.Now().Add(expiresIn)
+	}
+	return accessToken, nil
+}
+
+// forget drops the cached token, for example after the API answered 401.
+func (c *oauth2TokenCache) forget(oauth2 spec.HTTPOAuth2, clientSecret string) {
+	entry := c.entry(oauth2CacheKey(oauth2, clientSecret))
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.accessToken = ""
+	entry.expiresAt = time.Time{}
+}
+
+// oauth2CacheKey identifies one token grant. The secret is part of the key so
+// a rotated secret fetches a new token.
+func oauth2CacheKey(oauth2 spec.HTTPOAuth2, clientSecret string) string {
+	return strings.Join([]string{
+		strings.TrimSpace(oauth2.TokenURL),
+		strings.TrimSpace(oauth2.ClientID),
+		clientSecret,
+		strings.Join(oauth2.Scopes, " "),
+		strings.TrimSpace(oauth2.Audience),
+	}, "\x00")
+}
+
+func requestOAuth2Token(ctx context.Context, client *http.Client, oauth2 spec.HTTPOAuth2, clientSecret string) (string, time.Duration, error) {
+	clientID := strings.TrimSpace(oauth2.ClientID)
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(oauth2.Scopes) > 0 {
+		form.Set("scope", strings.Join(oauth2.Scopes, " "))
+	}
+	if audience := strings.TrimSpace(oauth2.Audience); audience != "" {
+		form.Set("audience", audience)
+	}
+	if strings.TrimSpace(oauth2.ClientAuth) == "body" {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(oauth2.TokenURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("build oauth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if strings.TrimSpace(oauth2.ClientAuth) != "body" {
+		// RFC 6749 section 2.3.1 form-encodes the credentials before Basic auth.
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("oauth2 token request: %w", describeTLSError(err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("read oauth2 token response: %w", err)
+	}
+
+	var token struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	decodeErr := json.Unmarshal(body, &token)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if decodeErr == nil && token.Error != "" {
+			return "", 0, fmt.Errorf("oauth2 token endpoint returned %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+		}
+		return "", 0, fmt.Errorf("oauth2 token endpoint returned %d: %s", resp.StatusCode, truncateForError(string(body)))
+	}
+	if decodeErr != nil {
+		return "", 0, fmt.Errorf("parse oauth2 token response: %w", decodeErr)
+	}
+	if token.AccessToken == "" {
+		return "", 0, fmt.Errorf("oauth2 token response has no access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported oauth2 token_type %q", token.TokenType)
+	}
+	var expiresIn time.Duration
+	if token.ExpiresIn != "" {
+		seconds, err := token.ExpiresIn.Int64()
+		if err != nil {
+			return "", 0, fmt.Errorf("parse oauth2 expires_in: %w", err)
+		}
+		expiresIn = time.Duration(seconds) * time.Second
+	}
+	return token.AccessToken, expiresIn, nil
+}
+
+// applyHTTPAuth sets the Authorization header of req. It returns a function
+// to report the response status, which drops a cached OAuth2 token that the
+// server rejected with 401 so the next cycle fetches a new one.
+func applyHTTPAuth(ctx context.Context, req *http.Request, auth *spec.HTTPAuth, client *http.Client) (func(statusCode int), error) {
+	noop := func(int) {}
+	if auth == nil {
+		return noop, nil
+	}
+
+	switch {
+	case auth.Basic != nil:
+		basic := auth.Basic
+		username, err := resolveSecret("auth.basic.username", basic.Username, basic.UsernameEnv, basic.UsernameFile)
+		if err != nil {
+			return nil, err
+		}
+		password, err := resolveSecret("auth.basic.password", basic.Password, basic.PasswordEnv, basic.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(username, password)
+		return noop, nil
+	case auth.Bearer != nil:
+		bearer := auth.Bearer
+		token, err := resolveSecret("auth.bearer.token", bearer.Token, bearer.TokenEnv, bearer.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(token))
+		return noop, nil
+	case auth.OAuth2 != nil:
+		oauth2 := *auth.OAuth2
+		clientSecret, err := resolveSecret("auth.oauth2.client_secret", oauth2.ClientSecret, oauth2.ClientSecretEnv, oauth2.ClientSecretFile)
+		if err != nil {
+			return nil, err
+		}
+		cache := oauth2TokenCacheFrom(ctx)
+		token, err := cache.token(ctx, client, oauth2, clientSecret)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return func(statusCode int) {
+			if statusCode == http.StatusUnauthorized {
+				cache.forget(oauth2, clientSecret)
+			}
+		}, nil
+	default:
+		return noop, nil
+	}
+}
@@ -0,0 +1,225 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func TestValidateHTTPSpecStaticAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); ok {
+			fmt.Fprintf(w, "basic %s:%s", username, password)
+			return
+		}
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	t.Setenv("EDDIE_TEST_API_PASSWORD", "s3cret")
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	writeTestFile(t, tokenPath, "abc.def.ghi\n", time.Now())
+
+	tests := []struct {
+		name string
+		auth *spec.HTTPAuth
+		want string
+	}{
+		{
+			name: "basic with password from env",
+			auth: &spec.HTTPAuth{Basic: &spec.HTTPBasicAuth{Username: "eddie", PasswordEnv: "EDDIE_TEST_API_PASSWORD"}},
+			want: "basic eddie:s3cret",
+		},
+		{
+			name: "bearer from file",
+			auth: &spec.HTTPAuth{Bearer: &spec.HTTPBearerAuth{TokenFile: tokenPath}},
+			want: "Bearer abc.def.ghi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHTTPSpec(context.Background(), spec.Spec{
+				HTTP: &spec.HTTPSpec{
+					Name:   "auth",
+					URL:    server.URL,
+					Auth:   tt.auth,
+					Expect: spec.HTTPExpect{Body: spec.HTTPExpectBody{Exact: tt.want}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+			}
+		})
+	}
+
+	err := validateHTTPSpec(context.Background(), spec.Spec{
+		HTTP: &spec.HTTPSpec{
+			Name: "auth",
+			URL:  server.URL,
+			Auth: &spec.HTTPAuth{Bearer: &spec.HTTPBearerAuth{TokenEnv: "EDDIE_TEST_UNSET_TOKEN"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `auth.bearer.token_env: environment variable "EDDIE_TEST_UNSET_TOKEN" is not set`) {
+		t.Fatalf("validateHTTPSpec() error = %v, want missing env error", err)
+	}
+}
+
+// startOAuth2TokenServer issues "token-N" for client eddie/secret. The
+// credentials are accepted via Basic auth or in the form body.
+func startOAuth2TokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("grant_type") != "client_credentials" || clientID != "eddie" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"unknown client"}`)
+			return
+		}
+		if r.PostForm.Get("scope") != "status:read metrics:read" || r.PostForm.Get("audience") != "https://api.example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_scope"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, issued.Add(1), expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func TestValidateHTTPSpecOAuth2ClientCredentials(t *testing.T) {
+	var rejectNext atomic.Bool
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rejectNext.Swap(false) || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}))
+	defer api.Close()
+
+	oauth2Spec := func(tokenURL, clientAuth string) *spec.HTTPOAuth2 {
+		return &spec.HTTPOAuth2{
+			TokenURL:     tokenURL,
+			ClientID:     "eddie",
+			ClientSecret: "secret",
+			ClientAuth:   clientAuth,
+			Scopes:       []string{"status:read", "metrics:read"},
+			Audience:     "https://api.example.com",
+		}
+	}
+	check := func(ctx context.Context, oauth2 *spec.HTTPOAuth2, wantBody string) error {
+		return validateHTTPSpec(ctx, spec.Spec{
+			HTTP: &spec.HTTPSpec{
+				Name:   "oauth2",
+				URL:    api.URL,
+				Auth:   &spec.HTTPAuth{OAuth2: oauth2},
+				Expect: spec.HTTPExpect{Code: http.StatusOK, Body: spec.HTTPExpectBody{Exact: wantBody}},
+			},
+		})
+	}
+
+	t.Run("cached across cycles", func(t *testing.T) {
+		tokenServer, issued := startOAuth2TokenServer(t, 3600)
+		ctx := withOAuth2TokenCache(context.Background(), newOAuth2TokenCache())
+		for cycle := 0; cycle < 3; cycle++ {
+			if err := check(ctx, oauth2Spec(tokenServer.URL, ""), "token-1"); err != nil {
+				t.Fatalf("cycle %d: validateHTTPSpec() error = %v, want nil", cycle, err)
+			}
+		}
+		if got := issued.Load(); got != 1 {
+			t.Fatalf("issued tokens = %d, want 1", got)
+		}
+	})
+
+	t.Run("refreshed before expiry", func(t *testing.T) {
+		tokenServer, issued := startOAuth2TokenServer(t, 10)
+		ctx := withOAuth2TokenCache(context.Background(), newOAuth2TokenCache())
+		if err := check(ctx, oauth2Spec(tokenServer.URL, "body"), "token-1"); err != nil {
+			t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+		}
+		if err := check(ctx, oauth2Spec(tokenServer.URL, "body"), "token-2"); err != nil {
+			t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+		}
+		if got := issued.Load(); got != 2 {
+			t.Fatalf("issued tokens = %d, want 2", got)
+		}
+	})
+
+	t.Run("dropped after 401", func(t *testing.T) {
+		tokenServer, _ := startOAuth2TokenServer(t, 3600)
+		ctx := withOAuth2TokenCache(context.Background(), newOAuth2TokenCache())
+		rejectNext.Store(true)
+		if err := check(ctx, oauth2Spec(tokenServer.URL, ""), "token-1"); err == nil {
+			t.Fatalf("validateHTTPSpec() error = nil, want 401 failure")
+		}
+		if err := check(ctx, oauth2Spec(tokenServer.URL, ""), "token-2"); err != nil {
+			t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("rejected client", func(t *testing.T) {
+		tokenServer, _ := startOAuth2TokenServer(t, 3600)
+		oauth2 := oauth2Spec(tokenServer.URL, "")
+		oauth2.ClientSecret = "wrong"
+		err := check(context.Background(), oauth2, "")
+		if err == nil || !strings.Contains(err.Error(), "oauth2 token endpoint returned 401: invalid_client unknown client") {
+			t.Fatalf("validateHTTPSpec() error = %v, want invalid_client", err)
+		}
+	})
+}
+
+func TestValidateHTTPSpecExcludesOAuth2TokenFetchFromTimings(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"slow-token","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer api.Close()
+
+	// Token fetch plus request exceed both the timeout and max_duration; the
+	// request alone stays within them.
+	ctx, recorder := withTimingRecorder(context.Background())
+	err := validateHTTPSpec(ctx, spec.Spec{
+		HTTP: &spec.HTTPSpec{
+			Name:    "oauth2",
+			URL:     api.URL,
+			Timeout: 400 * time.Millisecond,
+			Auth: &spec.HTTPAuth{OAuth2: &spec.HTTPOAuth2{
+				TokenURL:     tokenServer.URL,
+				ClientID:     "eddie",
+				ClientSecret: "secret",
+			}},
+			Expect: spec.HTTPExpect{
+				Body:             spec.HTTPExpectBody{Exact: "Bearer slow-token"},
+				HTTPTimingExpect: spec.HTTPTimingExpect{MaxDuration: 250 * time.Millisecond},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+	}
+	timings := recorder.Timings()
+	if len(timings) != 1 || timings[0].Total >= 300*time.Millisecond {
+		t.Fatalf("timings = %v, want one request without the token fetch", timings)
+	}
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// buildHTTPBody encodes the configured request body and returns it together
+// with its Content-Type. A nil body yields a nil reader. expand, when set, is
+// applied to raw text and form/multipart field values.
+func buildHTTPBody(body *spec.HTTPBody, expand func(string) string) (io.Reader, string, error) {
+	if body == nil {
+		return nil, "", nil
+	}
+	if expand == nil {
+		expand = func(value string) string { return value }
+	}
+	contentType := strings.TrimSpace(body.ContentType)
+
+	switch {
+	case body.JSON != nil:
+		encoded, err := json.Marshal(body.JSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("encode json body: %w", err)
+		}
+		return bytes.NewReader(encoded), contentTypeOrDefault(contentType, "application/json"), nil
+	case len(body.Form) > 0:
+		values := make(url.Values, len(body.Form))
+		for key, value := range body.Form {
+			values.Set(key, expand(value))
+		}
+		return strings.NewReader(values.Encode()), contentTypeOrDefault(contentType, "application/x-www-form-urlencoded"), nil
+	case body.Multipart != nil:
+		return buildMultipartBody(*body.Multipart, expand)
+	case strings.TrimSpace(body.File) != "":
+		path := strings.TrimSpace(body.File)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("read body file: %w", err)
+		}
+		return bytes.NewReader(content), contentTypeOrDefault(contentType, contentTypeForPath(path)), nil
+	default:
+		return strings.NewReader(expand(body.Raw)), contentTypeOrDefault(contentType, "text/plain; charset=utf-8"), nil
+	}
+}
+
+func buildMultipartBody(body spec.HTTPMultipart, expand func(string) string) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	names := make([]string, 0, len(body.Fields))
+	for name := range body.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writer.WriteField(name, expand(body.Fields[name])); err != nil {
+			return nil, "", fmt.Errorf("write multipart field %q: %w", name, err)
+		}
+	}
+
+	for _, file := range body.Files {
+		path := strings.TrimSpace(file.Path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("read multipart file %q: %w", file.Field, err)
+		}
+		filename := strings.TrimSpace(file.Filename)
+		if filename == "" {
+			filename = filepath.Base(path)
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     strings.TrimSpace(file.Field),
+			"filename": filename,
+		}))
+		header.Set("Content-Type", contentTypeOrDefault(strings.TrimSpace(file.ContentType), contentTypeForPath(path)))
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("write multipart file %q: %w", file.Field, err)
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", fmt.Errorf("write multipart file %q: %w", file.Field, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("close multipart body: %w", err)
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
+
+func contentTypeOrDefault(contentType, fallback string) string {
+	if contentType != "" {
+		return contentType
+	}
+	return fallback
+}
+
+func contentTypeForPath(path string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
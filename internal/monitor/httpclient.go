@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+type defaultHTTPProxyKey struct{}
+
+// withDefaultHTTPProxy returns a context whose HTTP checks use proxy unless
+// the spec sets its own. An empty proxy keeps the environment proxy settings.
+func withDefaultHTTPProxy(ctx context.Context, proxy string) context.Context {
+	if strings.TrimSpace(proxy) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, defaultHTTPProxyKey{}, strings.TrimSpace(proxy))
+}
+
+// httpProxyFunc resolves the proxy for one check: the spec proxy, else the
+// global proxy, else HTTP_PROXY/HTTPS_PROXY/NO_PROXY from the environment.
+// "none" connects directly.
+func httpProxyFunc(ctx context.Context, specProxy string) (func(*http.Request) (*url.URL, error), error) {
+	proxy := strings.TrimSpace(specProxy)
+	if proxy == "" {
+		proxy, _ = ctx.Value(defaultHTTPProxyKey{}).(string)
+	}
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case spec.ProxyNone:
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL")
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// newHTTPTransport returns a transport for one check request. Keep-alives
+// are disabled so every request opens a fresh connection and its timing
+// covers DNS, connect and TLS instead of reusing a pooled connection.
+func newHTTPTransport(ctx context.Context, clientTLS spec.ClientTLS, insecureSkipTLS bool, proxy string) (*http.Transport, error) {
+	tlsConfig, err := buildClientTLSConfig(clientTLS, insecureSkipTLS)
+	if err != nil {
+		return nil, err
+	}
+	proxyFunc, err := httpProxyFunc(ctx, proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxyFunc
+	return transport, nil
+}
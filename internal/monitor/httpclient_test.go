@@ -0,0 +1,300 @@
+package monitor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// startHTTPProxy runs a forward and CONNECT proxy that requires the
+// credentials eddie/s3cret. It returns the proxy address and a counter of
+// proxied requests.
+func startHTTPProxy(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	var proxied atomic.Int32
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("eddie:s3cret"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != wantAuth {
+			w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		proxied.Add(1)
+		if r.Method != http.MethodConnect {
+			outReq := r.Clone(r.Context())
+			outReq.RequestURI = ""
+			outReq.Header.Del("Proxy-Authorization")
+			resp, err := http.DefaultTransport.RoundTrip(outReq)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer resp.Body.Close()
+			w.WriteHeader(resp.StatusCode)
+			_, _ = io.Copy(w, resp.Body)
+			return
+		}
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		pipeConns(conn, upstream)
+	}))
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String(), &proxied
+}
+
+// startSOCKS5Proxy runs a SOCKS5 proxy that requires the username/password
+// eddie/s3cret.
+func startSOCKS5Proxy(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	var proxied atomic.Int32
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		// Greeting: version, method count, methods. Offer username/password.
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte{0x05, 0x02})
+
+		username, password, err := readSOCKS5Credentials(conn)
+		if err != nil {
+			return
+		}
+		if username != "eddie" || password != "s3cret" {
+			_, _ = conn.Write([]byte{0x01, 0x01})
+			return
+		}
+		_, _ = conn.Write([]byte{0x01, 0x00})
+
+		// Request: version, CONNECT, reserved, address type, address, port.
+		request := make([]byte, 4)
+		if _, err := io.ReadFull(conn, request); err != nil || request[1] != 0x01 {
+			return
+		}
+		var targetHost string
+		switch request[3] {
+		case 0x01, 0x04:
+			addr := make([]byte, map[byte]int{0x01: 4, 0x04: 16}[request[3]])
+			if _, err := io.ReadFull(conn, addr); err != nil {
+				return
+			}
+			targetHost = net.IP(addr).String()
+		case 0x03:
+			length := make([]byte, 1)
+			if _, err := io.ReadFull(conn, length); err != nil {
+				return
+			}
+			name := make([]byte, length[0])
+			if _, err := io.ReadFull(conn, name); err != nil {
+				return
+			}
+			targetHost = string(name)
+		default:
+			return
+		}
+		portBytes := make([]byte, 2)
+		if _, err := io.ReadFull(conn, portBytes); err != nil {
+			return
+		}
+		upstream, err := net.Dial("tcp", net.JoinHostPort(targetHost, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes)))))
+		if err != nil {
+			_, _ = conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer upstream.Close()
+		proxied.Add(1)
+		_, _ = conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		pipeConns(conn, upstream)
+	})
+	return net.JoinHostPort(host, strconv.Itoa(port)), &proxied
+}
+
+func readSOCKS5Credentials(conn net.Conn) (string, string, error) {
+	readField := func() (string, error) {
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		value := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, value); err != nil {
+			return "", err
+		}
+		return string(value), nil
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		return "", "", err
+	}
+	username, err := readField()
+	if err != nil {
+		return "", "", err
+	}
+	password, err := readField()
+	if err != nil {
+		return "", "", err
+	}
+	return username, password, nil
+}
+
+func pipeConns(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func TestValidateHTTPSpecThroughProxy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "behind proxy")
+	})
+	plainServer := httptest.NewServer(handler)
+	defer plainServer.Close()
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	httpProxy, httpProxied := startHTTPProxy(t)
+	socksProxy, socksProxied := startSOCKS5Proxy(t)
+
+	tests := []struct {
+		name    string
+		url     string
+		proxy   string
+		proxied *atomic.Int32
+		wantErr string
+	}{
+		{
+			name:    "http forward proxy",
+			url:     plainServer.URL,
+			proxy:   "http://eddie:s3cret@" + httpProxy,
+			proxied: httpProxied,
+		},
+		{
+			name:    "http connect proxy",
+			url:     tlsServer.URL,
+			proxy:   "http://eddie:s3cret@" + httpProxy,
+			proxied: httpProxied,
+		},
+		{
+			name:    "socks5 proxy",
+			url:     tlsServer.URL,
+			proxy:   "socks5://eddie:s3cret@" + socksProxy,
+			proxied: socksProxied,
+		},
+		{
+			name:    "connect proxy rejects credentials",
+			url:     tlsServer.URL,
+			proxy:   "http://eddie:wrong@" + httpProxy,
+			wantErr: "Proxy Authentication Required",
+		},
+		{
+			name:    "socks5 proxy rejects credentials",
+			url:     tlsServer.URL,
+			proxy:   "socks5://eddie:wrong@" + socksProxy,
+			wantErr: "username/password authentication failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before int32
+			if tt.proxied != nil {
+				before = tt.proxied.Load()
+			}
+			err := validateHTTPSpec(context.Background(), spec.Spec{
+				HTTP: &spec.HTTPSpec{
+					Name:            "proxied",
+					URL:             tt.url,
+					Proxy:           tt.proxy,
+					InsecureSkipTLS: true,
+					Expect:          spec.HTTPExpect{Code: http.StatusOK, Body: spec.HTTPExpectBody{Exact: "behind proxy"}},
+				},
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validateHTTPSpec() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+			}
+			if got := tt.proxied.Load() - before; got != 1 {
+				t.Fatalf("proxied requests = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPSpecGlobalProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+	httpProxy, proxied := startHTTPProxy(t)
+
+	check := func(ctx context.Context, proxy string) error {
+		return validateHTTPSpec(ctx, spec.Spec{
+			HTTP: &spec.HTTPSpec{
+				Name:   "global",
+				URL:    server.URL,
+				Proxy:  proxy,
+				Expect: spec.HTTPExpect{Code: http.StatusOK},
+			},
+		})
+	}
+
+	ctx := withDefaultHTTPProxy(context.Background(), "http://eddie:s3cret@"+httpProxy)
+	if err := check(ctx, ""); err != nil {
+		t.Fatalf("validateHTTPSpec() error = %v, want nil", err)
+	}
+	if got := proxied.Load(); got != 1 {
+		t.Fatalf("proxied requests = %d, want 1", got)
+	}
+	if err := check(ctx, "none"); err != nil {
+		t.Fatalf("validateHTTPSpec(proxy none) error = %v, want nil", err)
+	}
+	if got := proxied.Load(); got != 1 {
+		t.Fatalf("proxied requests after proxy none = %d, want 1", got)
+	}
+
+	// An unreachable global proxy fails checks that do not opt out.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	ctx = withDefaultHTTPProxy(context.Background(), closed.URL)
+	if err := check(ctx, ""); err == nil || !strings.Contains(err.Error(), "proxyconnect") {
+		t.Fatalf("validateHTTPSpec() error = %v, want proxyconnect failure", err)
+	}
+	if err := check(ctx, "none"); err != nil {
+		t.Fatalf("validateHTTPSpec(proxy none) error = %v, want nil", err)
+	}
+}
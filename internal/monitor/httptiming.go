@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/fabiant7t/eddie/internal/state"
+)
+
+type timingRecorderKey struct{}
+
+// timingRecorder collects the request timings of one check run.
+type timingRecorder struct {
+	mu      sync.Mutex
+	timings []state.RequestTiming
+}
+
+// withTimingRecorder returns a context whose HTTP requests report their
+// timings to the returned recorder.
+func withTimingRecorder(ctx context.Context) (context.Context, *timingRecorder) {
+	recorder := &timingRecorder{}
+	return context.WithValue(ctx, timingRecorderKey{}, recorder), recorder
+}
+
+func recordRequestTiming(ctx context.Context, timing state.RequestTiming) {
+	recorder, ok := ctx.Value(timingRecorderKey{}).(*timingRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.timings = append(recorder.timings, timing)
+}
+
+// Timings returns the recorded timings in request order.
+func (r *timingRecorder) Timings() []state.RequestTiming {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]state.RequestTiming(nil), r.timings...)
+}
+
+// requestTimer measures the phases of one HTTP request, including redirects.
+// DNS, connect and TLS add up across hops; TTFB is measured from the start of
+// the request to the first byte of the last response.
+type requestTimer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       state.RequestTiming
+}
+
+func newRequestTimer(label string) *requestTimer {
+	return &requestTimer{start: time.Now(), timing: state.RequestTiming{Label: label}}
+}
+
+func (t *requestTimer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNS += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Parallel dials (happy eyeballs) count from the first attempt.
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && !t.connectStart.IsZero() {
+				t.timing.Connect += time.Since(t.connectStart)
+				t.connectStart = time.Time{}
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLS += time.Since(t.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TTFB = time.Since(t.start)
+		},
+	}
+}
+
+// finish stops the timer and returns the measured phases.
+func (t *requestTimer) finish() state.RequestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timing.Total = time.Since(t.start)
+	return t.timing
+}
+
+func checkRequestTiming(timing state.RequestTiming, expect spec.HTTPTimingExpect) error {
+	for _, limit := range []struct {
+		name  string
+		got   time.Duration
+		limit time.Duration
+	}{
+		{"dns", timing.DNS, expect.MaxPhase.DNS},
+		{"connect", timing.Connect, expect.MaxPhase.Connect},
+		{"tls", timing.TLS, expect.MaxPhase.TLS},
+		{"ttfb", timing.TTFB, expect.MaxPhase.TTFB},
+	} {
+		if limit.limit > 0 && limit.got > limit.limit {
+			return fmt.Errorf("%s took %s, exceeds max_phase.%s %s", limit.name, limit.got.Round(time.Millisecond), limit.name, limit.limit)
+		}
+	}
+	if expect.MaxDuration > 0 && timing.Total > expect.MaxDuration {
+		return fmt.Errorf("request took %s, exceeds max_duration %s", timing.Total.Round(time.Millisecond), expect.MaxDuration)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func validateLDAPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	ldapSpec := parsedSpec.LDAP
+	if ldapSpec == nil {
+		return fmt.Errorf("missing ldap spec")
+	}
+
+	timeout := ldapSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rawURL := strings.TrimSpace(ldapSpec.URL)
+	serverURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse ldap.url: %w", err)
+	}
+	var tlsConfig *tls.Config
+	if serverURL.Scheme == "ldaps" || ldapSpec.StartTLS {
+		tlsConfig, err = buildClientTLSConfig(ldapSpec.TLS, ldapSpec.InsecureSkipTLS)
+		if err != nil {
+			return err
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = serverURL.Hostname()
+		}
+	}
+
+	dialOptions := []ldap.DialOpt{ldap.DialWithDialer(&net.Dialer{Timeout: timeout})}
+	if serverURL.Scheme == "ldaps" {
+		dialOptions = append(dialOptions, ldap.DialWithTLSConfig(tlsConfig))
+	}
+	conn, err := ldap.DialURL(rawURL, dialOptions...)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(timeout)
+	stop := context.AfterFunc(checkCtx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	if ldapSpec.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if ldapSpec.CertMinDaysValid != nil {
+		state, _ := conn.TLSConnectionState()
+		peerCertificate := firstPeerCertificate(state)
+		if peerCertificate == nil {
+			return fmt.Errorf("no peer certificate presented")
+		}
+		if err := checkCertMinDaysValid(peerCertificate, *ldapSpec.CertMinDaysValid); err != nil {
+			return err
+		}
+	}
+
+	if bindDN := strings.TrimSpace(ldapSpec.BindDN); bindDN != "" {
+		password, err := resolveSecret("ldap.bind_password", ldapSpec.BindPassword, ldapSpec.BindPasswordEnv, ldapSpec.BindPasswordFile)
+		if err != nil {
+			return err
+		}
+		if err := conn.Bind(bindDN, password); err != nil {
+			return fmt.Errorf("bind as %q: %w", bindDN, err)
+		}
+	}
+
+	if search := ldapSpec.Search; search != nil {
+		if err := runLDAPSearch(conn, *search); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runLDAPSearch(conn *ldap.Conn, search spec.LDAPSearch) error {
+	filter := strings.TrimSpace(search.Filter)
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	scope := ldap.ScopeWholeSubtree
+	switch strings.ToLower(strings.TrimSpace(search.Scope)) {
+	case "base":
+		scope = ldap.ScopeBaseObject
+	case "one":
+		scope = ldap.ScopeSingleLevel
+	}
+	names := make([]string, 0, len(search.Expect.Attributes))
+	for name := range search.Expect.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	attributes := make([]string, 0, len(names))
+	for _, name := range names {
+		attributes = append(attributes, strings.TrimSpace(name))
+	}
+	if len(attributes) == 0 {
+		// Only the DNs are needed; "1.1" asks for no attributes (RFC 4511).
+		attributes = append(attributes, "1.1")
+	}
+
+	request := ldap.NewSearchRequest(
+		strings.TrimSpace(search.BaseDN), scope, ldap.NeverDerefAliases,
+		0, 0, false, filter, attributes, nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return fmt.Errorf("search %s: %w", filter, err)
+	}
+
+	count := len(result.Entries)
+	expect := search.Expect
+	if expect.CountGT != nil && !(count > *expect.CountGT) {
+		return fmt.Errorf("unexpected entry count: got %d, want > %d", count, *expect.CountGT)
+	}
+	if expect.CountGTE != nil && !(count >= *expect.CountGTE) {
+		return fmt.Errorf("unexpected entry count: got %d, want >= %d", count, *expect.CountGTE)
+	}
+	if expect.CountEQ != nil && count != *expect.CountEQ {
+		return fmt.Errorf("unexpected entry count: got %d, want == %d", count, *expect.CountEQ)
+	}
+
+	if len(expect.Attributes) == 0 {
+		return nil
+	}
+	if count == 0 {
+		return fmt.Errorf("search %s returned no entries to check attributes on", filter)
+	}
+	entry := result.Entries[0]
+	for _, key := range names {
+		name := strings.TrimSpace(key)
+		valueExpect := expect.Attributes[key]
+		values := entry.GetEqualFoldAttributeValues(name)
+		if len(values) == 0 {
+			return fmt.Errorf("entry %q has no attribute %s", entry.DN, name)
+		}
+		var lastErr error
+		for _, value := range values {
+			if lastErr = checkResponseExpect(valueExpect, value); lastErr == nil {
+				break
+			}
+		}
+		if lastErr != nil {
+			return fmt.Errorf("entry %q attribute %s: %w", entry.DN, name, lastErr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+type ldapTestEntry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+var ldapTestDirectory = []ldapTestEntry{
+	{
+		dn: "uid=alice,ou=people,dc=example,dc=com",
+		attributes: map[string][]string{
+			"uid":      {"alice"},
+			"mail":     {"alice@example.com"},
+			"memberOf": {"cn=staff,ou=groups,dc=example,dc=com", "cn=admins,ou=groups,dc=example,dc=com"},
+		},
+	},
+	{
+		dn: "uid=bob,ou=people,dc=example,dc=com",
+		attributes: map[string][]string{
+			"uid":  {"bob"},
+			"mail": {"bob@example.com"},
+		},
+	},
+}
+
+// startLDAPServer runs a minimal LDAPv3 server with simple bind for
+// "cn=eddie,dc=example,dc=com"/"secret", StartTLS, and searches supporting
+// presence and equality filters. With implicitTLS the listener speaks ldaps.
+func startLDAPServer(t *testing.T, certificate tls.Certificate, implicitTLS bool) string {
+	t.Helper()
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}}
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if implicitTLS {
+			tlsConn := tls.Server(conn, tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		}
+		reader := bufio.NewReader(conn)
+		for {
+			request, err := ber.ReadPacket(reader)
+			if err != nil || len(request.Children) < 2 {
+				return
+			}
+			messageID := request.Children[0].Value
+			op := request.Children[1]
+			switch op.Tag {
+			case 0: // bind
+				name := op.Children[1].Data.String()
+				password := op.Children[2].Data.String()
+				code := int64(0)
+				if name != "cn=eddie,dc=example,dc=com" || password != "secret" {
+					code = 49 // invalidCredentials
+				}
+				writeLDAPResult(conn, messageID, 1, code)
+			case 2: // unbind
+				return
+			case 3: // search
+				for _, entry := range ldapTestDirectory {
+					if !ldapTestFilterMatches(op.Children[6], entry) {
+						continue
+					}
+					writeLDAPEntry(conn, messageID, entry, op.Children[7])
+				}
+				writeLDAPResult(conn, messageID, 5, 0)
+			case 23: // extended: StartTLS
+				writeLDAPResult(conn, messageID, 24, 0)
+				tlsConn := tls.Server(conn, tlsConfig)
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+				conn = tlsConn
+				reader = bufio.NewReader(conn)
+			default:
+				return
+			}
+		}
+	})
+
+	scheme := "ldap"
+	if implicitTLS {
+		scheme = "ldaps"
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func ldapTestFilterMatches(filter *ber.Packet, entry ldapTestEntry) bool {
+	switch filter.Tag {
+	case 7: // present
+		return filter.Data.String() == "objectClass" || len(entry.attributes[filter.Data.String()]) > 0
+	case 3: // equalityMatch
+		attribute := filter.Children[0].Data.String()
+		value := filter.Children[1].Data.String()
+		for _, candidate := range entry.attributes[attribute] {
+			if candidate == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeLDAPResult(conn net.Conn, messageID any, tag ber.Tag, code int64) {
+	message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	message.AppendChild(result)
+	_, _ = conn.Write(message.Bytes())
+}
+
+func writeLDAPEntry(conn net.Conn, messageID any, entry ldapTestEntry, requested *ber.Packet) {
+	message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "SearchResultEntry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "objectName"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for _, child := range requested.Children {
+		for name, values := range entry.attributes {
+			if !strings.EqualFold(name, child.Data.String()) {
+				continue
+			}
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+	}
+	result.AppendChild(attributes)
+	message.AppendChild(result)
+	_, _ = conn.Write(message.Bytes())
+}
+
+func TestValidateLDAPSpecPlainBind(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, false)
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:         "sso",
+		URL:          url,
+		Timeout:      2 * time.Second,
+		BindDN:       "cn=eddie,dc=example,dc=com",
+		BindPassword: "secret",
+	}})
+	if err != nil {
+		t.Fatalf("validateLDAPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateLDAPSpecLDAPSSearchWithAttributes(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, true)
+	one, minDays := 1, 30
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:             "sso",
+		URL:              url,
+		Timeout:          2 * time.Second,
+		BindDN:           "cn=eddie,dc=example,dc=com",
+		BindPassword:     "secret",
+		InsecureSkipTLS:  true,
+		CertMinDaysValid: &minDays,
+		Search: &spec.LDAPSearch{
+			BaseDN: "ou=people,dc=example,dc=com",
+			Filter: "(uid=alice)",
+			Expect: spec.LDAPSearchExpect{
+				CountEQ: &one,
+				Attributes: map[string]spec.ResponseExpect{
+					"mail":     {Exact: "alice@example.com"},
+					"memberof": {Contains: "cn=admins"},
+				},
+			},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("validateLDAPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateLDAPSpecStartTLSSearchCount(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, false)
+	one, minDays := 1, 30
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:             "sso",
+		URL:              url,
+		Timeout:          2 * time.Second,
+		BindDN:           "cn=eddie,dc=example,dc=com",
+		BindPassword:     "secret",
+		StartTLS:         true,
+		InsecureSkipTLS:  true,
+		CertMinDaysValid: &minDays,
+		Search:           &spec.LDAPSearch{BaseDN: "dc=example,dc=com", Expect: spec.LDAPSearchExpect{CountGT: &one}},
+	}})
+	if err != nil {
+		t.Fatalf("validateLDAPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateLDAPSpecWrongPassword(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, false)
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:         "sso",
+		URL:          url,
+		Timeout:      2 * time.Second,
+		BindDN:       "cn=eddie,dc=example,dc=com",
+		BindPassword: "wrong",
+	}})
+	if err == nil || !strings.Contains(err.Error(), "bind as") {
+		t.Fatalf("validateLDAPSpec() error = %v, want bind failure", err)
+	}
+}
+
+func TestValidateLDAPSpecCertificateExpiresSoon(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(5*24*time.Hour))
+	url := startLDAPServer(t, certificate, true)
+	minDays := 14
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:             "sso",
+		URL:              url,
+		Timeout:          2 * time.Second,
+		BindDN:           "cn=eddie,dc=example,dc=com",
+		BindPassword:     "secret",
+		InsecureSkipTLS:  true,
+		CertMinDaysValid: &minDays,
+	}})
+	if err == nil || !strings.Contains(err.Error(), "certificate expires too soon") {
+		t.Fatalf("validateLDAPSpec() error = %v, want certificate expiry failure", err)
+	}
+}
+
+func TestValidateLDAPSpecUntrustedCertificate(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, true)
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:         "sso",
+		URL:          url,
+		Timeout:      2 * time.Second,
+		BindDN:       "cn=eddie,dc=example,dc=com",
+		BindPassword: "secret",
+	}})
+	if err == nil || !strings.Contains(err.Error(), "connect:") {
+		t.Fatalf("validateLDAPSpec() error = %v, want untrusted certificate", err)
+	}
+}
+
+func TestValidateLDAPSpecSearchNoMatch(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, false)
+	one := 1
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:         "sso",
+		URL:          url,
+		Timeout:      2 * time.Second,
+		BindDN:       "cn=eddie,dc=example,dc=com",
+		BindPassword: "secret",
+		Search:       &spec.LDAPSearch{Filter: "(uid=carol)", Expect: spec.LDAPSearchExpect{CountGTE: &one}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "unexpected entry count: got 0, want >= 1") {
+		t.Fatalf("validateLDAPSpec() error = %v, want entry count failure", err)
+	}
+}
+
+func TestValidateLDAPSpecSearchAttributeMismatch(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(90*24*time.Hour))
+	url := startLDAPServer(t, certificate, false)
+
+	err := validateLDAPSpec(context.Background(), spec.Spec{LDAP: &spec.LDAPSpec{
+		Name:         "sso",
+		URL:          url,
+		Timeout:      2 * time.Second,
+		BindDN:       "cn=eddie,dc=example,dc=com",
+		BindPassword: "secret",
+		Search: &spec.LDAPSearch{
+			Filter: "(uid=bob)",
+			Expect: spec.LDAPSearchExpect{Attributes: map[string]spec.ResponseExpect{"memberOf": {Contains: "cn=admins"}}},
+		},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "has no attribute memberOf") {
+		t.Fatalf("validateLDAPSpec() error = %v, want attribute failure", err)
+	}
+}
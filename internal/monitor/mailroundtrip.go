@@ -0,0 +1,317 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	netmail "net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	imapclient "github.com/emersion/go-imap/client"
+	"github.com/fabiant7t/eddie/internal/mail"
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+const mailRoundTripSubjectPrefix = "eddie mail round-trip "
+
+// mailRoundTripStaleAge is the minimum age of leftover round-trip messages
+// before a check deletes them. It keeps tokens that another check on the same
+// mailbox is still waiting for.
+const mailRoundTripStaleAge = time.Hour
+
+func (r *Runner) validateMailRoundTripSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	roundTrip := parsedSpec.MailRoundTrip
+	if roundTrip == nil {
+		return fmt.Errorf("missing mail_roundtrip spec")
+	}
+
+	timeout := roundTrip.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	pollInterval := roundTrip.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+
+	relay := r.mailService
+	if roundTrip.SMTP != nil {
+		var err error
+		relay, err = newMailRoundTripRelay(*roundTrip.SMTP)
+		if err != nil {
+			return err
+		}
+	}
+	if relay == nil {
+		return fmt.Errorf("no smtp relay configured: set mail_roundtrip.smtp or the global mail settings")
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	mailbox, err := openRoundTripMailbox(roundTrip.IMAP, timeout)
+	if err != nil {
+		return err
+	}
+	defer mailbox.Logout()
+
+	staleBefore := time.Now().Add(-max(timeout, mailRoundTripStaleAge))
+	if err := purgeStaleRoundTripMessages(mailbox, staleBefore); err != nil {
+		slog.Warn("mail_roundtrip_cleanup_failed",
+			"name", parsedSpec.Name(),
+			"error", err,
+		)
+	}
+
+	token, err := newRoundTripToken()
+	if err != nil {
+		return err
+	}
+	subject := mailRoundTripSubjectPrefix + token
+	body := fmt.Sprintf("This message was sent by eddie to verify mail delivery for %q.\r\ntoken: %s\r\n", parsedSpec.Name(), token)
+
+	sentAt := time.Now()
+	if err := relay.Send(checkCtx, strings.TrimSpace(roundTrip.Recipient), subject, body); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	uids, err := waitForRoundTripMessage(checkCtx, mailbox, token, pollInterval)
+	if err != nil {
+		return fmt.Errorf("message %s not delivered within %s: %w", token, timeout, err)
+	}
+	latency := time.Since(sentAt)
+
+	header, fetchErr := fetchRoundTripHeader(mailbox, uids)
+	deleteErr := deleteRoundTripMessages(mailbox, uids)
+	if fetchErr != nil {
+		return fetchErr
+	}
+
+	if roundTrip.Expect.MaxLatency > 0 && latency > roundTrip.Expect.MaxLatency {
+		return fmt.Errorf("delivery latency %s exceeds %s", latency.Round(time.Millisecond), roundTrip.Expect.MaxLatency)
+	}
+	if err := checkRoundTripHeaders(header, roundTrip.Expect); err != nil {
+		return err
+	}
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	slog.Debug("mail_roundtrip_delivered",
+		"name", parsedSpec.Name(),
+		"latency", latency.String(),
+	)
+	return nil
+}
+
+func newMailRoundTripRelay(relay spec.MailRoundTripSMTP) (*mail.Service, error) {
+	password, err := resolveSecret("mail_roundtrip.smtp.password", relay.Password, relay.PasswordEnv, relay.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	opts := make([]mail.Option, 0, 2)
+	if relay.Port > 0 {
+		opts = append(opts, mail.WithPort(relay.Port))
+	}
+	if relay.NoTLS {
+		opts = append(opts, mail.WithNoTLS())
+	}
+	service, err := mail.New(strings.TrimSpace(relay.Host), strings.TrimSpace(relay.Username), password, strings.TrimSpace(relay.Sender), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("configure smtp relay: %w", err)
+	}
+	return service, nil
+}
+
+func openRoundTripMailbox(settings spec.MailRoundTripIMAP, timeout time.Duration) (*imapclient.Client, error) {
+	host := strings.TrimSpace(settings.Host)
+	port := settings.Port
+	if port <= 0 {
+		port = 993
+		if settings.StartTLS || settings.NoTLS {
+			port = 143
+		}
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: settings.InsecureSkipTLS,
+		MinVersion:         tls.VersionTLS12,
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var client *imapclient.Client
+	var err error
+	if settings.StartTLS || settings.NoTLS {
+		client, err = imapclient.DialWithDialer(dialer, address)
+	} else {
+		client, err = imapclient.DialWithDialerTLS(dialer, address, tlsConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("imap connect: %w", err)
+	}
+	client.Timeout = timeout
+
+	if settings.StartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Logout()
+			return nil, fmt.Errorf("imap starttls: %w", err)
+		}
+	}
+
+	password, err := resolveSecret("mail_roundtrip.imap.password", settings.Password, settings.PasswordEnv, settings.PasswordFile)
+	if err != nil {
+		_ = client.Logout()
+		return nil, err
+	}
+	if err := client.Login(strings.TrimSpace(settings.Username), password); err != nil {
+		_ = client.Logout()
+		return nil, fmt.Errorf("imap login: %w", err)
+	}
+
+	mailboxName := strings.TrimSpace(settings.Mailbox)
+	if mailboxName == "" {
+		mailboxName = "INBOX"
+	}
+	if _, err := client.Select(mailboxName, false); err != nil {
+		_ = client.Logout()
+		return nil, fmt.Errorf("imap select %q: %w", mailboxName, err)
+	}
+	return client, nil
+}
+
+func waitForRoundTripMessage(ctx context.Context, client *imapclient.Client, token string, pollInterval time.Duration) ([]uint32, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.Header.Add("Subject", token)
+
+	for {
+		if err := client.Noop(); err != nil {
+			return nil, fmt.Errorf("imap noop: %w", err)
+		}
+		uids, err := client.UidSearch(criteria)
+		if err != nil {
+			return nil, fmt.Errorf("imap search: %w", err)
+		}
+		if len(uids) > 0 {
+			return uids, nil
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func fetchRoundTripHeader(client *imapclient.Client, uids []uint32) (netmail.Header, error) {
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids[0])
+	section := &imap.BodySectionName{
+		BodyPartName: imap.BodyPartName{Specifier: imap.HeaderSpecifier},
+		Peek:         true,
+	}
+
+	messages := make(chan *imap.Message, 1)
+	if err := client.UidFetch(seqSet, []imap.FetchItem{section.FetchItem()}, messages); err != nil {
+		return nil, fmt.Errorf("imap fetch: %w", err)
+	}
+	message := <-messages
+	if message == nil {
+		return nil, fmt.Errorf("imap fetch: message %d not returned", uids[0])
+	}
+	literal := message.GetBody(section)
+	if literal == nil {
+		return nil, fmt.Errorf("imap fetch: message %d has no header section", uids[0])
+	}
+	parsed, err := netmail.ReadMessage(bufio.NewReader(literal))
+	if err != nil {
+		return nil, fmt.Errorf("parse message header: %w", err)
+	}
+	return parsed.Header, nil
+}
+
+func deleteRoundTripMessages(client *imapclient.Client, uids []uint32) error {
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+	flags := []interface{}{imap.DeletedFlag}
+	if err := client.UidStore(seqSet, imap.FormatFlagsOp(imap.AddFlags, true), flags, nil); err != nil {
+		return fmt.Errorf("imap delete: %w", err)
+	}
+	if err := client.Expunge(nil); err != nil {
+		return fmt.Errorf("imap expunge: %w", err)
+	}
+	return nil
+}
+
+// purgeStaleRoundTripMessages deletes round-trip messages that arrived before
+// staleBefore, such as tokens delivered after their check had given up.
+func purgeStaleRoundTripMessages(client *imapclient.Client, staleBefore time.Time) error {
+	criteria := imap.NewSearchCriteria()
+	criteria.Header.Add("Subject", strings.TrimSpace(mailRoundTripSubjectPrefix))
+	uids, err := client.UidSearch(criteria)
+	if err != nil {
+		return fmt.Errorf("imap search: %w", err)
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+	messages := make(chan *imap.Message, len(uids))
+	if err := client.UidFetch(seqSet, []imap.FetchItem{imap.FetchUid, imap.FetchInternalDate}, messages); err != nil {
+		return fmt.Errorf("imap fetch: %w", err)
+	}
+	var stale []uint32
+	for message := range messages {
+		if message.InternalDate.Before(staleBefore) {
+			stale = append(stale, message.Uid)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return deleteRoundTripMessages(client, stale)
+}
+
+func checkRoundTripHeaders(header netmail.Header, expect spec.MailRoundTripExpect) error {
+	for _, name := range expect.Headers {
+		if len(header[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))]) == 0 {
+			return fmt.Errorf("delivered message has no %s header", strings.TrimSpace(name))
+		}
+	}
+	for name, want := range expect.HeaderContains {
+		values := header[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))]
+		found := false
+		for _, value := range values {
+			if strings.Contains(value, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("delivered message header %s %q does not contain %q", strings.TrimSpace(name), values, want)
+		}
+	}
+	return nil
+}
+
+func newRoundTripToken() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,332 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/backend/memory"
+	imapserver "github.com/emersion/go-imap/server"
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func startIMAPServer(t *testing.T) (*memory.Backend, string, int) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	backend := memory.New()
+	server := imapserver.New(backend)
+	server.AllowInsecureAuth = true
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	host, port := splitHostPortForTest(t, listener.Addr().String())
+	return backend, host, port
+}
+
+// startDeliveringSMTPServer accepts plaintext PLAIN auth for alice/secret and
+// delivers every message into the INBOX of the memory backend, prefixed with
+// extraHeaders.
+func startDeliveringSMTPServer(t *testing.T, backend *memory.Backend, extraHeaders string) (string, int) {
+	t.Helper()
+
+	return startTCPServer(t, func(conn net.Conn) {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+		reply := func(line string) {
+			_, _ = conn.Write([]byte(line + "\r\n"))
+		}
+
+		reply("220 mx.example.test ESMTP ready")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-mx.example.test")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(command, "AUTH PLAIN "):
+				decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN "):]))
+				if string(decoded) == "\x00alice\x00secret" {
+					reply("235 2.7.0 Authentication successful")
+				} else {
+					reply("535 5.7.8 Authentication credentials invalid")
+				}
+			case strings.HasPrefix(command, "MAIL FROM:"), strings.HasPrefix(command, "RCPT TO:"):
+				reply("250 ok")
+			case command == "DATA":
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var message strings.Builder
+				message.WriteString(extraHeaders)
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					message.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				user, err := backend.Login(nil, "username", "password")
+				if err != nil {
+					reply("451 mailbox unavailable")
+					continue
+				}
+				mailbox, err := user.GetMailbox("INBOX")
+				if err != nil {
+					reply("451 mailbox unavailable")
+					continue
+				}
+				if err := mailbox.CreateMessage(nil, time.Now(), strings.NewReader(message.String())); err != nil {
+					reply("451 delivery failed")
+					continue
+				}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 command not implemented")
+			}
+		}
+	})
+}
+
+func inboxSubjects(t *testing.T, backend *memory.Backend) []string {
+	t.Helper()
+
+	user, err := backend.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	mailbox, err := user.GetMailbox("INBOX")
+	if err != nil {
+		t.Fatalf("GetMailbox() error = %v", err)
+	}
+	var subjects []string
+	for _, message := range mailbox.(*memory.Mailbox).Messages {
+		for _, line := range strings.Split(string(message.Body), "\r\n") {
+			if strings.HasPrefix(line, "Subject: ") {
+				subjects = append(subjects, strings.TrimPrefix(line, "Subject: "))
+			}
+		}
+	}
+	return subjects
+}
+
+func TestValidateMailRoundTripSpecDeliversAndDeletes(t *testing.T) {
+	backend, imapHost, imapPort := startIMAPServer(t)
+	smtpHost, smtpPort := startDeliveringSMTPServer(t, backend,
+		"DKIM-Signature: v=1; a=rsa-sha256; d=example.test; s=mail\r\n"+
+			"Authentication-Results: mx.example.test; spf=pass smtp.mailfrom=example.test; dkim=pass\r\n")
+
+	runner := &Runner{}
+	err := runner.validateMailRoundTripSpec(context.Background(), spec.Spec{
+		MailRoundTrip: &spec.MailRoundTripSpec{
+			Name:      "delivery",
+			Recipient: "username@example.test",
+			SMTP: &spec.MailRoundTripSMTP{
+				Host:     smtpHost,
+				Port:     smtpPort,
+				Username: "alice",
+				Password: "secret",
+				Sender:   "eddie@example.test",
+				NoTLS:    true,
+			},
+			IMAP: spec.MailRoundTripIMAP{
+				Host:     imapHost,
+				Port:     imapPort,
+				Username: "username",
+				Password: "password",
+				NoTLS:    true,
+			},
+			Timeout:      3 * time.Second,
+			PollInterval: 50 * time.Millisecond,
+			Expect: spec.MailRoundTripExpect{
+				MaxLatency:     2 * time.Second,
+				Headers:        []string{"dkim-signature"},
+				HeaderContains: map[string]string{"Authentication-Results": "spf=pass"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateMailRoundTripSpec() error = %v, want nil", err)
+	}
+
+	for _, subject := range inboxSubjects(t, backend) {
+		if strings.HasPrefix(subject, mailRoundTripSubjectPrefix) {
+			t.Fatalf("inbox still contains test message %q", subject)
+		}
+	}
+}
+
+func TestValidateMailRoundTripSpecPurgesStaleMessages(t *testing.T) {
+	backend, imapHost, imapPort := startIMAPServer(t)
+	smtpHost, smtpPort := startDeliveringSMTPServer(t, backend, "")
+
+	user, err := backend.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	inbox, err := user.GetMailbox("INBOX")
+	if err != nil {
+		t.Fatalf("GetMailbox() error = %v", err)
+	}
+	for subject, arrived := range map[string]time.Time{
+		mailRoundTripSubjectPrefix + "late":      time.Now().Add(-2 * time.Hour),
+		mailRoundTripSubjectPrefix + "in-flight": time.Now().Add(-time.Minute),
+	} {
+		message := "From: eddie@example.test\r\nSubject: " + subject + "\r\n\r\ntoken\r\n"
+		if err := inbox.CreateMessage(nil, arrived, bytes.NewBufferString(message)); err != nil {
+			t.Fatalf("CreateMessage() error = %v", err)
+		}
+	}
+
+	runner := &Runner{}
+	err = runner.validateMailRoundTripSpec(context.Background(), spec.Spec{
+		MailRoundTrip: &spec.MailRoundTripSpec{
+			Name:      "delivery",
+			Recipient: "username@example.test",
+			SMTP: &spec.MailRoundTripSMTP{
+				Host:     smtpHost,
+				Port:     smtpPort,
+				Username: "alice",
+				Password: "secret",
+				Sender:   "eddie@example.test",
+				NoTLS:    true,
+			},
+			IMAP: spec.MailRoundTripIMAP{
+				Host:     imapHost,
+				Port:     imapPort,
+				Username: "username",
+				Password: "password",
+				NoTLS:    true,
+			},
+			Timeout:      3 * time.Second,
+			PollInterval: 50 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateMailRoundTripSpec() error = %v, want nil", err)
+	}
+
+	subjects := strings.Join(inboxSubjects(t, backend), "\n")
+	if strings.Contains(subjects, mailRoundTripSubjectPrefix+"late") {
+		t.Fatalf("inbox subjects = %q, want the late token purged", subjects)
+	}
+	if !strings.Contains(subjects, mailRoundTripSubjectPrefix+"in-flight") {
+		t.Fatalf("inbox subjects = %q, want the in-flight token kept", subjects)
+	}
+}
+
+func TestValidateMailRoundTripSpecMissingHeader(t *testing.T) {
+	backend, imapHost, imapPort := startIMAPServer(t)
+	smtpHost, smtpPort := startDeliveringSMTPServer(t, backend, "")
+
+	runner := &Runner{}
+	err := runner.validateMailRoundTripSpec(context.Background(), spec.Spec{
+		MailRoundTrip: &spec.MailRoundTripSpec{
+			Name:      "delivery",
+			Recipient: "username@example.test",
+			SMTP: &spec.MailRoundTripSMTP{
+				Host:     smtpHost,
+				Port:     smtpPort,
+				Username: "alice",
+				Password: "secret",
+				Sender:   "eddie@example.test",
+				NoTLS:    true,
+			},
+			IMAP: spec.MailRoundTripIMAP{
+				Host:     imapHost,
+				Port:     imapPort,
+				Username: "username",
+				Password: "password",
+				NoTLS:    true,
+			},
+			Timeout:      3 * time.Second,
+			PollInterval: 50 * time.Millisecond,
+			Expect:       spec.MailRoundTripExpect{Headers: []string{"DKIM-Signature"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no DKIM-Signature header") {
+		t.Fatalf("validateMailRoundTripSpec() error = %v, want missing header", err)
+	}
+	for _, subject := range inboxSubjects(t, backend) {
+		if strings.HasPrefix(subject, mailRoundTripSubjectPrefix) {
+			t.Fatalf("inbox still contains test message %q after failed assertion", subject)
+		}
+	}
+}
+
+func TestValidateMailRoundTripSpecNotDelivered(t *testing.T) {
+	_, imapHost, imapPort := startIMAPServer(t)
+	discard, _, _ := startIMAPServer(t)
+	smtpHost, smtpPort := startDeliveringSMTPServer(t, discard, "")
+
+	runner := &Runner{}
+	err := runner.validateMailRoundTripSpec(context.Background(), spec.Spec{
+		MailRoundTrip: &spec.MailRoundTripSpec{
+			Name:      "delivery",
+			Recipient: "username@example.test",
+			SMTP: &spec.MailRoundTripSMTP{
+				Host:     smtpHost,
+				Port:     smtpPort,
+				Username: "alice",
+				Password: "secret",
+				Sender:   "eddie@example.test",
+				NoTLS:    true,
+			},
+			IMAP: spec.MailRoundTripIMAP{
+				Host:     imapHost,
+				Port:     imapPort,
+				Username: "username",
+				Password: "password",
+				NoTLS:    true,
+			},
+			Timeout:      300 * time.Millisecond,
+			PollInterval: 50 * time.Millisecond,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not delivered within") {
+		t.Fatalf("validateMailRoundTripSpec() error = %v, want not delivered", err)
+	}
+}
+
+func TestValidateMailRoundTripSpecRequiresRelay(t *testing.T) {
+	runner := &Runner{}
+	err := runner.validateMailRoundTripSpec(context.Background(), spec.Spec{
+		MailRoundTrip: &spec.MailRoundTripSpec{
+			Name:      "delivery",
+			Recipient: "username@example.test",
+			IMAP: spec.MailRoundTripIMAP{
+				Host:     "127.0.0.1",
+				Port:     1,
+				Username: "username",
+				Password: "password",
+				NoTLS:    true,
+			},
+			Timeout:      3 * time.Second,
+			PollInterval: 50 * time.Millisecond,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no smtp relay configured") {
+		t.Fatalf("validateMailRoundTripSpec() error = %v, want relay error", err)
+	}
+}
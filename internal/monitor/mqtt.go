@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func validateMQTTSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	mqttSpec := parsedSpec.MQTT
+	if mqttSpec == nil {
+		return fmt.Errorf("missing mqtt spec")
+	}
+
+	timeout := mqttSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	opts, err := buildMQTTClientOptions(*mqttSpec, timeout)
+	if err != nil {
+		return err
+	}
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(client.Connect(), deadline, "connect"); err != nil {
+		return err
+	}
+	defer client.Disconnect(250)
+
+	if retained := mqttSpec.Retained; retained != nil {
+		if err := checkMQTTRetained(client, *retained, deadline); err != nil {
+			return err
+		}
+	}
+	if roundTrip := mqttSpec.RoundTrip; roundTrip != nil {
+		if err := runMQTTRoundTrip(client, *roundTrip, deadline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildMQTTClientOptions(mqttSpec spec.MQTTSpec, timeout time.Duration) (*mqtt.ClientOptions, error) {
+	broker := strings.TrimSpace(mqttSpec.Broker)
+	clientID := strings.TrimSpace(mqttSpec.ClientID)
+	if clientID == "" {
+		token, err := newRoundTripToken()
+		if err != nil {
+			return nil, err
+		}
+		clientID = "eddie-" + token
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetCleanSession(true).
+		SetAutoReconnect(false).
+		SetConnectRetry(false).
+		SetConnectTimeout(timeout).
+		SetWriteTimeout(timeout).
+		SetOrderMatters(false)
+
+	if username := strings.TrimSpace(mqttSpec.Username); username != "" {
+		opts.SetUsername(username)
+	}
+	password, err := resolveSecret("mqtt.password", mqttSpec.Password, mqttSpec.PasswordEnv, mqttSpec.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		opts.SetPassword(password)
+	}
+
+	brokerURL, err := url.Parse(broker)
+	if err != nil {
+		return nil, fmt.Errorf("parse mqtt.broker: %w", err)
+	}
+	switch brokerURL.Scheme {
+	case "ssl", "tls", "mqtts", "wss":
+		tlsConfig, err := buildClientTLSConfig(mqttSpec.TLS, mqttSpec.InsecureSkipTLS)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = brokerURL.Hostname()
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	return opts, nil
+}
+
+func waitMQTTToken(token mqtt.Token, deadline time.Time, action string) error {
+	if !token.WaitTimeout(time.Until(deadline)) {
+		return fmt.Errorf("%s: timed out", action)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	return nil
+}
+
+func runMQTTRoundTrip(client mqtt.Client, roundTrip spec.MQTTRoundTrip, deadline time.Time) error {
+	topic := strings.TrimSpace(roundTrip.Topic)
+	qos := byte(roundTrip.QoS)
+	token, err := newRoundTripToken()
+	if err != nil {
+		return err
+	}
+	payload := "eddie-" + token
+
+	received := make(chan struct{}, 1)
+	subscribe := client.Subscribe(topic, qos, func(_ mqtt.Client, message mqtt.Message) {
+		if string(message.Payload()) == payload {
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+		}
+	})
+	if err := waitMQTTToken(subscribe, deadline, "subscribe "+topic); err != nil {
+		return err
+	}
+	defer client.Unsubscribe(topic)
+
+	sentAt := time.Now()
+	if err := waitMQTTToken(client.Publish(topic, qos, false, payload), deadline, "publish "+topic); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-received:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("round trip on %s: message not received within %s", topic, time.Since(sentAt).Round(time.Millisecond))
+	}
+}
+
+func checkMQTTRetained(client mqtt.Client, retained spec.MQTTRetained, deadline time.Time) error {
+	topic := strings.TrimSpace(retained.Topic)
+	messages := make(chan mqtt.Message, 1)
+	subscribe := client.Subscribe(topic, 0, func(_ mqtt.Client, message mqtt.Message) {
+		if !message.Retained() {
+			return
+		}
+		select {
+		case messages <- message:
+		default:
+		}
+	})
+	if err := waitMQTTToken(subscribe, deadline, "subscribe "+topic); err != nil {
+		return err
+	}
+	defer client.Unsubscribe(topic)
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case message := <-messages:
+		if err := checkJSONPathExpect(string(message.Payload()), retained.JSONPath); err != nil {
+			return fmt.Errorf("retained message on %s: %w", topic, err)
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("no retained message on %s", topic)
+	}
+}
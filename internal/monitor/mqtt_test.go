@@ -0,0 +1,345 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// mqttTestBroker is a minimal MQTT 3.1.1 broker: exact topic matching,
+// retained messages and QoS 0/1 publishes, forwarded to subscribers as QoS 0.
+type mqttTestBroker struct {
+	mu          sync.Mutex
+	retained    map[string][]byte
+	subscribers map[string][]*mqttTestSession
+	dropPublish bool
+}
+
+type mqttTestSession struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *mqttTestSession) write(packet []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.conn.Write(packet)
+}
+
+func startMQTTBroker(t *testing.T, certificate *tls.Certificate) (*mqttTestBroker, string) {
+	t.Helper()
+
+	broker := &mqttTestBroker{
+		retained:    make(map[string][]byte),
+		subscribers: make(map[string][]*mqttTestSession),
+	}
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		if certificate != nil {
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*certificate}})
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		}
+		broker.serve(conn)
+	})
+	scheme := "tcp"
+	if certificate != nil {
+		scheme = "ssl"
+	}
+	return broker, scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (b *mqttTestBroker) serve(conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+	session := &mqttTestSession{conn: conn}
+	defer b.unsubscribeAll(session)
+
+	for {
+		header, body, err := readMQTTPacket(reader)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case 1: // CONNECT
+			returnCode := byte(0)
+			if username, password := parseMQTTConnectCredentials(body); username != "device" || password != "secret" {
+				returnCode = 5
+			}
+			session.write([]byte{0x20, 2, 0, returnCode})
+			if returnCode != 0 {
+				return
+			}
+		case 3: // PUBLISH
+			qos := (header >> 1) & 0x03
+			topicLength := int(binary.BigEndian.Uint16(body))
+			topic := string(body[2 : 2+topicLength])
+			offset := 2 + topicLength
+			if qos > 0 {
+				packetID := body[offset : offset+2]
+				offset += 2
+				session.write([]byte{0x40, 2, packetID[0], packetID[1]})
+			}
+			payload := append([]byte(nil), body[offset:]...)
+			if header&0x01 == 1 {
+				b.mu.Lock()
+				b.retained[topic] = payload
+				b.mu.Unlock()
+			}
+			b.publish(topic, payload, false)
+		case 8: // SUBSCRIBE
+			packetID := body[:2]
+			granted := []byte{}
+			var topics []string
+			for offset := 2; offset < len(body); {
+				topicLength := int(binary.BigEndian.Uint16(body[offset:]))
+				topics = append(topics, string(body[offset+2:offset+2+topicLength]))
+				offset += 2 + topicLength + 1
+				granted = append(granted, 0)
+			}
+			session.write(append([]byte{0x90, byte(2 + len(granted)), packetID[0], packetID[1]}, granted...))
+			for _, topic := range topics {
+				b.mu.Lock()
+				b.subscribers[topic] = append(b.subscribers[topic], session)
+				payload, ok := b.retained[topic]
+				b.mu.Unlock()
+				if ok {
+					session.write(encodeMQTTPublish(topic, payload, true))
+				}
+			}
+		case 10: // UNSUBSCRIBE
+			session.write([]byte{0xB0, 2, body[0], body[1]})
+		case 12: // PINGREQ
+			session.write([]byte{0xD0, 0})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func (b *mqttTestBroker) publish(topic string, payload []byte, retained bool) {
+	b.mu.Lock()
+	drop := b.dropPublish
+	sessions := append([]*mqttTestSession(nil), b.subscribers[topic]...)
+	b.mu.Unlock()
+	if drop {
+		return
+	}
+	for _, session := range sessions {
+		session.write(encodeMQTTPublish(topic, payload, retained))
+	}
+}
+
+func (b *mqttTestBroker) unsubscribeAll(session *mqttTestSession) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for topic, sessions := range b.subscribers {
+		kept := sessions[:0]
+		for _, candidate := range sessions {
+			if candidate != session {
+				kept = append(kept, candidate)
+			}
+		}
+		b.subscribers[topic] = kept
+	}
+}
+
+func readMQTTPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func encodeMQTTPublish(topic string, payload []byte, retained bool) []byte {
+	header := byte(0x30)
+	if retained {
+		header |= 0x01
+	}
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(body, topic...)
+	body = append(body, payload...)
+
+	packet := []byte{header}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// parseMQTTConnectCredentials reads username and password from a CONNECT body.
+func parseMQTTConnectCredentials(body []byte) (string, string) {
+	readString := func(offset int) (string, int) {
+		length := int(binary.BigEndian.Uint16(body[offset:]))
+		return string(body[offset+2 : offset+2+length]), offset + 2 + length
+	}
+	_, offset := readString(0) // protocol name
+	flags := body[offset+1]
+	offset += 4 // level, flags, keep alive
+	_, offset = readString(offset)
+	if flags&0x04 != 0 {
+		_, offset = readString(offset)
+		_, offset = readString(offset)
+	}
+	var username, password string
+	if flags&0x80 != 0 {
+		username, offset = readString(offset)
+	}
+	if flags&0x40 != 0 {
+		password, _ = readString(offset)
+	}
+	return username, password
+}
+
+func TestValidateMQTTSpecRoundTripAndRetained(t *testing.T) {
+	broker, address := startMQTTBroker(t, nil)
+	broker.retained["fleet/status"] = []byte(`{"online":true,"devices":{"count":12}}`)
+
+	err := validateMQTTSpec(context.Background(), spec.Spec{
+		MQTT: &spec.MQTTSpec{
+			Name:      "fleet",
+			Broker:    address,
+			Username:  "device",
+			Password:  "secret",
+			Timeout:   2 * time.Second,
+			RoundTrip: &spec.MQTTRoundTrip{Topic: "eddie/canary", QoS: 1},
+			Retained: &spec.MQTTRetained{
+				Topic:    "fleet/status",
+				JSONPath: map[string]any{"online": true, "devices.count": 12},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateMQTTSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateMQTTSpecTLS(t *testing.T) {
+	certificate := selfSignedCertificate(t, time.Now().Add(24*time.Hour))
+	_, address := startMQTTBroker(t, &certificate)
+
+	err := validateMQTTSpec(context.Background(), spec.Spec{
+		MQTT: &spec.MQTTSpec{
+			Name:            "fleet",
+			Broker:          address,
+			Username:        "device",
+			Password:        "secret",
+			Timeout:         2 * time.Second,
+			InsecureSkipTLS: true,
+			RoundTrip:       &spec.MQTTRoundTrip{Topic: "eddie/canary"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateMQTTSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateMQTTSpecBadAuth(t *testing.T) {
+	_, address := startMQTTBroker(t, nil)
+
+	err := validateMQTTSpec(context.Background(), spec.Spec{
+		MQTT: &spec.MQTTSpec{
+			Name:     "fleet",
+			Broker:   address,
+			Username: "device",
+			Password: "wrong",
+			Timeout:  2 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "connect:") {
+		t.Fatalf("validateMQTTSpec() error = %v, want connect failure", err)
+	}
+}
+
+func TestValidateMQTTSpecLostMessage(t *testing.T) {
+	broker, address := startMQTTBroker(t, nil)
+	broker.dropPublish = true
+
+	err := validateMQTTSpec(context.Background(), spec.Spec{
+		MQTT: &spec.MQTTSpec{
+			Name:      "fleet",
+			Broker:    address,
+			Username:  "device",
+			Password:  "secret",
+			Timeout:   300 * time.Millisecond,
+			RoundTrip: &spec.MQTTRoundTrip{Topic: "eddie/canary"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "message not received") {
+		t.Fatalf("validateMQTTSpec() error = %v, want lost message", err)
+	}
+}
+
+func TestValidateMQTTSpecRetainedMismatch(t *testing.T) {
+	broker, address := startMQTTBroker(t, nil)
+	broker.retained["fleet/status"] = []byte(`{"online":false}`)
+
+	err := validateMQTTSpec(context.Background(), spec.Spec{
+		MQTT: &spec.MQTTSpec{
+			Name:     "fleet",
+			Broker:   address,
+			Username: "device",
+			Password: "secret",
+			Timeout:  2 * time.Second,
+			Retained: &spec.MQTTRetained{Topic: "fleet/status", JSONPath: map[string]any{"online": true}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "retained message on fleet/status") {
+		t.Fatalf("validateMQTTSpec() error = %v, want retained mismatch", err)
+	}
+}
+
+func TestValidateMQTTSpecRetainedMissing(t *testing.T) {
+	_, address := startMQTTBroker(t, nil)
+
+	err := validateMQTTSpec(context.Background(), spec.Spec{
+		MQTT: &spec.MQTTSpec{
+			Name:     "fleet",
+			Broker:   address,
+			Username: "device",
+			Password: "secret",
+			Timeout:  300 * time.Millisecond,
+			Retained: &spec.MQTTRetained{Topic: "fleet/unknown"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no retained message") {
+		t.Fatalf("validateMQTTSpec() error = %v, want missing retained message", err)
+	}
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 and 1970-01-01.
+const ntpEpochOffset = 2208988800
+
+const ntpLeapUnsynchronized = 3
+
+type ntpResponse struct {
+	leap           byte
+	stratum        byte
+	referenceID    [4]byte
+	rootDelay      time.Duration
+	rootDispersion time.Duration
+	offset         time.Duration
+	rtt            time.Duration
+}
+
+// rootDistance estimates the maximum error of the server clock relative to
+// its reference (RFC 5905, simplified to a single sample).
+func (r ntpResponse) rootDistance() time.Duration {
+	return (r.rtt+r.rootDelay)/2 + r.rootDispersion
+}
+
+func validateNTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	ntpSpec := parsedSpec.NTP
+	if ntpSpec == nil {
+		return fmt.Errorf("missing ntp spec")
+	}
+
+	timeout := ntpSpec.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	server := strings.TrimSpace(ntpSpec.Server)
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "123")
+	}
+	response, err := queryNTP(queryCtx, server)
+	if err != nil {
+		return err
+	}
+
+	if response.leap == ntpLeapUnsynchronized {
+		return fmt.Errorf("server %s reports leap indicator unsynchronized (stratum %d)", server, response.stratum)
+	}
+	expect := ntpSpec.Expect
+	if expect.MaxStratum > 0 && int(response.stratum) > expect.MaxStratum {
+		return fmt.Errorf("stratum %d exceeds max_stratum %d", response.stratum, expect.MaxStratum)
+	}
+	if expect.MaxOffset > 0 && response.offset.Abs() > expect.MaxOffset {
+		return fmt.Errorf("clock offset %s exceeds max_offset %s", response.offset, expect.MaxOffset)
+	}
+	if expect.MaxRootDistance > 0 && response.rootDistance() > expect.MaxRootDistance {
+		return fmt.Errorf("root distance %s exceeds max_root_distance %s", response.rootDistance(), expect.MaxRootDistance)
+	}
+	return nil
+}
+
+// queryNTP sends a single NTPv4 client request and parses the reply.
+func queryNTP(ctx context.Context, server string) (ntpResponse, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return ntpResponse{}, fmt.Errorf("dial %s: %w", server, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// The transmit timestamp is random; the server echoes it as origin
+	// timestamp, which ties the reply to this request.
+	request := make([]byte, 48)
+	request[0] = 0<<6 | 4<<3 | 3 // LI 0, version 4, mode 3 (client)
+	if _, err := rand.Read(request[40:48]); err != nil {
+		return ntpResponse{}, err
+	}
+	sentAt := time.Now()
+	if _, err := conn.Write(request); err != nil {
+		return ntpResponse{}, fmt.Errorf("send to %s: %w", server, err)
+	}
+
+	reply := make([]byte, 512)
+	for {
+		n, err := conn.Read(reply)
+		if err != nil {
+			return ntpResponse{}, fmt.Errorf("read from %s: %w", server, err)
+		}
+		receivedAt := time.Now()
+		if n < 48 || string(reply[24:32]) != string(request[40:48]) {
+			continue
+		}
+		return parseNTPResponse(reply[:n], sentAt, receivedAt)
+	}
+}
+
+func parseNTPResponse(reply []byte, sentAt, receivedAt time.Time) (ntpResponse, error) {
+	if mode := reply[0] & 0x07; mode != 4 {
+		return ntpResponse{}, fmt.Errorf("unexpected ntp mode %d in reply", mode)
+	}
+	response := ntpResponse{
+		leap:           reply[0] >> 6,
+		stratum:        reply[1],
+		rootDelay:      ntpShortDuration(binary.BigEndian.Uint32(reply[4:8])),
+		rootDispersion: ntpShortDuration(binary.BigEndian.Uint32(reply[8:12])),
+	}
+	copy(response.referenceID[:], reply[12:16])
+	if response.stratum == 0 {
+		// Kiss-o'-Death: the reference ID carries an ASCII code such as RATE or DENY.
+		return ntpResponse{}, fmt.Errorf("server sent kiss-o'-death %q", strings.TrimRight(string(response.referenceID[:]), "\x00"))
+	}
+
+	serverReceive := ntpTimestamp(binary.BigEndian.Uint64(reply[32:40]))
+	serverTransmit := ntpTimestamp(binary.BigEndian.Uint64(reply[40:48]))
+	response.offset = (serverReceive.Sub(sentAt) + serverTransmit.Sub(receivedAt)) / 2
+	response.rtt = max(receivedAt.Sub(sentAt)-serverTransmit.Sub(serverReceive), 0)
+	return response, nil
+}
+
+// ntpTimestamp converts a 64-bit NTP timestamp (32.32 fixed point seconds
+// since 1900) to a time.Time. Values before 1970 are read as NTP era 1,
+// which starts in 2036.
+func ntpTimestamp(value uint64) time.Time {
+	seconds := int64(value>>32) - ntpEpochOffset
+	if seconds < 0 {
+		seconds += 1 << 32
+	}
+	fraction := int64(value&0xffffffff) * int64(time.Second) >> 32
+	return time.Unix(seconds, fraction)
+}
+
+// ntpShortDuration converts a 32-bit NTP short format (16.16 fixed point
+// seconds) to a duration.
+func ntpShortDuration(value uint32) time.Duration {
+	return time.Duration(int64(value) * int64(time.Second) >> 16)
+}
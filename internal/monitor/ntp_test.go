@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+type ntpTestReply struct {
+	leap           byte
+	stratum        byte
+	referenceID    string
+	clockOffset    time.Duration
+	rootDelay      time.Duration
+	rootDispersion time.Duration
+}
+
+// startNTPServer answers NTP client requests on a local UDP socket with the
+// given header fields and a clock shifted by reply.clockOffset.
+func startNTPServer(t *testing.T, reply ntpTestReply) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 48 {
+				continue
+			}
+			now := time.Now().Add(reply.clockOffset)
+			packet := make([]byte, 48)
+			packet[0] = reply.leap<<6 | 4<<3 | 4
+			packet[1] = reply.stratum
+			binary.BigEndian.PutUint32(packet[4:8], uint32(reply.rootDelay*(1<<16)/time.Second))
+			binary.BigEndian.PutUint32(packet[8:12], uint32(reply.rootDispersion*(1<<16)/time.Second))
+			copy(packet[12:16], reply.referenceID)
+			copy(packet[24:32], buf[40:48])
+			binary.BigEndian.PutUint64(packet[32:40], ntpTestTimestamp(now))
+			binary.BigEndian.PutUint64(packet[40:48], ntpTestTimestamp(now))
+			_, _ = conn.WriteTo(packet, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func ntpTestTimestamp(at time.Time) uint64 {
+	seconds := uint64(at.Unix() + ntpEpochOffset)
+	fraction := uint64(at.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+func TestValidateNTPSpec(t *testing.T) {
+	server := startNTPServer(t, ntpTestReply{
+		stratum:        2,
+		referenceID:    "\xc0\x00\x02\x01",
+		clockOffset:    20 * time.Millisecond,
+		rootDelay:      10 * time.Millisecond,
+		rootDispersion: 5 * time.Millisecond,
+	})
+
+	parsedSpec := spec.Spec{NTP: &spec.NTPSpec{
+		Name:    "pool",
+		Server:  server,
+		Timeout: time.Second,
+		Expect: spec.NTPExpect{
+			MaxOffset:       100 * time.Millisecond,
+			MaxStratum:      3,
+			MaxRootDistance: 50 * time.Millisecond,
+		},
+	}}
+	if err := validateNTPSpec(context.Background(), parsedSpec); err != nil {
+		t.Fatalf("validateNTPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateNTPSpecClockDrift(t *testing.T) {
+	server := startNTPServer(t, ntpTestReply{stratum: 2, clockOffset: -3 * time.Second})
+
+	err := validateNTPSpec(context.Background(), spec.Spec{NTP: &spec.NTPSpec{
+		Name:    "pool",
+		Server:  server,
+		Timeout: time.Second,
+		Expect:  spec.NTPExpect{MaxOffset: 500 * time.Millisecond},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "exceeds max_offset 500ms") {
+		t.Fatalf("validateNTPSpec() error = %v, want offset failure", err)
+	}
+}
+
+func TestValidateNTPSpecStratumTooHigh(t *testing.T) {
+	server := startNTPServer(t, ntpTestReply{stratum: 9})
+
+	err := validateNTPSpec(context.Background(), spec.Spec{NTP: &spec.NTPSpec{
+		Name:    "pool",
+		Server:  server,
+		Timeout: time.Second,
+		Expect:  spec.NTPExpect{MaxStratum: 4},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "stratum 9 exceeds max_stratum 4") {
+		t.Fatalf("validateNTPSpec() error = %v, want stratum failure", err)
+	}
+}
+
+func TestValidateNTPSpecRootDistance(t *testing.T) {
+	server := startNTPServer(t, ntpTestReply{stratum: 3, rootDelay: 400 * time.Millisecond, rootDispersion: 300 * time.Millisecond})
+
+	err := validateNTPSpec(context.Background(), spec.Spec{NTP: &spec.NTPSpec{
+		Name:    "pool",
+		Server:  server,
+		Timeout: time.Second,
+		Expect:  spec.NTPExpect{MaxRootDistance: 250 * time.Millisecond},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "root distance") {
+		t.Fatalf("validateNTPSpec() error = %v, want root distance failure", err)
+	}
+}
+
+func TestValidateNTPSpecUnsynchronized(t *testing.T) {
+	server := startNTPServer(t, ntpTestReply{leap: ntpLeapUnsynchronized, stratum: 16})
+
+	err := validateNTPSpec(context.Background(), spec.Spec{NTP: &spec.NTPSpec{
+		Name:    "pool",
+		Server:  server,
+		Timeout: time.Second,
+	}})
+	if err == nil || !strings.Contains(err.Error(), "leap indicator unsynchronized") {
+		t.Fatalf("validateNTPSpec() error = %v, want unsynchronized failure", err)
+	}
+}
+
+func TestValidateNTPSpecKissOfDeath(t *testing.T) {
+	server := startNTPServer(t, ntpTestReply{stratum: 0, referenceID: "RATE"})
+
+	err := validateNTPSpec(context.Background(), spec.Spec{NTP: &spec.NTPSpec{
+		Name:    "pool",
+		Server:  server,
+		Timeout: time.Second,
+	}})
+	if err == nil || !strings.Contains(err.Error(), `kiss-o'-death "RATE"`) {
+		t.Fatalf("validateNTPSpec() error = %v, want kiss-o'-death", err)
+	}
+}
+
+func TestNTPTimestampRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 30, 0, 250_000_000, time.UTC)
+	got := ntpTimestamp(ntpTestTimestamp(at))
+	if diff := got.Sub(at).Abs(); diff > time.Microsecond {
+		t.Fatalf("ntpTimestamp() = %s, want %s", got, at)
+	}
+	if got := ntpTimestamp(uint64(1) << 32); got.Year() != 2036 {
+		t.Fatalf("ntpTimestamp(era 1) year = %d, want 2036", got.Year())
+	}
+}
@@ -0,0 +1,234 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolICMPv6   = 58
+	pingPayloadLabel = "eddie-ping"
+)
+
+type pingResult struct {
+	sent     int
+	received int
+	avgRTT   time.Duration
+	maxRTT   time.Duration
+}
+
+func (r pingResult) lossPercent() float64 {
+	if r.sent == 0 {
+		return 0
+	}
+	return float64(r.sent-r.received) * 100 / float64(r.sent)
+}
+
+func validatePingSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	pingSpec := parsedSpec.Ping
+	if pingSpec == nil {
+		return fmt.Errorf("missing ping spec")
+	}
+
+	host := strings.TrimSpace(pingSpec.Host)
+	count := pingSpec.Count
+	if count <= 0 {
+		count = 3
+	}
+	interval := pingSpec.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	timeout := pingSpec.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	target := addrs[0].IP
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			target = addr.IP
+			break
+		}
+	}
+
+	result, err := sendPings(ctx, target, count, interval, timeout)
+	if err != nil {
+		return err
+	}
+	slog.Debug("ping_result",
+		"name", parsedSpec.Name(),
+		"target", target.String(),
+		"sent", result.sent,
+		"received", result.received,
+		"avg_rtt", result.avgRTT.String(),
+		"max_rtt", result.maxRTT.String(),
+	)
+
+	if result.received == 0 {
+		return fmt.Errorf("no reply from %s (%d/%d packets lost)", target, result.sent, result.sent)
+	}
+	loss := result.lossPercent()
+	if maxLoss := pingSpec.Expect.MaxLossPercent; maxLoss != nil && loss > *maxLoss {
+		return fmt.Errorf("packet loss %.1f%% exceeds %.1f%% (%d/%d received)", loss, *maxLoss, result.received, result.sent)
+	}
+	if limit := pingSpec.Expect.MaxAvgRTT; limit > 0 && result.avgRTT > limit {
+		return fmt.Errorf("average rtt %s exceeds %s", result.avgRTT, limit)
+	}
+	if limit := pingSpec.Expect.MaxRTT; limit > 0 && result.maxRTT > limit {
+		return fmt.Errorf("max rtt %s exceeds %s", result.maxRTT, limit)
+	}
+	return nil
+}
+
+// pingEchoIDs hands out echo IDs for raw sockets. Specs ping concurrently, so
+// every call needs its own ID to keep replies to the same host apart.
+var pingEchoIDs atomic.Uint32
+
+func nextPingEchoID() int {
+	return int((uint32(os.Getpid()) + pingEchoIDs.Add(1)) & 0xffff)
+}
+
+// listenICMP opens an unprivileged ICMP datagram socket and falls back to a
+// raw socket when the kernel does not allow the former.
+func listenICMP(ipv6Target bool) (*icmp.PacketConn, bool, error) {
+	datagram, raw, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if ipv6Target {
+		datagram, raw, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+	conn, datagramErr := icmp.ListenPacket(datagram, address)
+	if datagramErr == nil {
+		return conn, false, nil
+	}
+	conn, rawErr := icmp.ListenPacket(raw, address)
+	if rawErr != nil {
+		return nil, false, fmt.Errorf("open icmp socket: %w", errors.Join(datagramErr, rawErr))
+	}
+	return conn, true, nil
+}
+
+func sendPings(ctx context.Context, target net.IP, count int, interval, timeout time.Duration) (pingResult, error) {
+	ipv6Target := target.To4() == nil
+	conn, raw, err := listenICMP(ipv6Target)
+	if err != nil {
+		return pingResult{}, err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	protocol := protocolICMP
+	if ipv6Target {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		protocol = protocolICMPv6
+	}
+	var destination net.Addr = &net.UDPAddr{IP: target}
+	if raw {
+		destination = &net.IPAddr{IP: target}
+	}
+	// Datagram sockets get their echo ID assigned by the kernel, which also
+	// filters replies; raw sockets see all ICMP traffic and match on the ID.
+	id := nextPingEchoID()
+
+	result := pingResult{}
+	var totalRTT time.Duration
+	buffer := make([]byte, 1500)
+	for seq := 1; seq <= count; seq++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		message := icmp.Message{
+			Type: requestType,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte(pingPayloadLabel)},
+		}
+		packet, err := message.Marshal(nil)
+		if err != nil {
+			return result, fmt.Errorf("marshal echo request: %w", err)
+		}
+
+		sentAt := time.Now()
+		if _, err := conn.WriteTo(packet, destination); err != nil {
+			return result, fmt.Errorf("send echo request: %w", err)
+		}
+		result.sent++
+
+		_ = conn.SetReadDeadline(sentAt.Add(timeout))
+		for {
+			n, peer, err := conn.ReadFrom(buffer)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return result, fmt.Errorf("read echo reply: %w", err)
+			}
+			if !pingPeerMatches(peer, target) {
+				continue
+			}
+			reply, err := icmp.ParseMessage(protocol, buffer[:n])
+			if err != nil || reply.Type != replyType {
+				continue
+			}
+			echo, ok := reply.Body.(*icmp.Echo)
+			if !ok || echo.Seq != seq || (raw && echo.ID != id) {
+				continue
+			}
+			rtt := time.Since(sentAt)
+			result.received++
+			totalRTT += rtt
+			if rtt > result.maxRTT {
+				result.maxRTT = rtt
+			}
+			break
+		}
+
+		if seq < count {
+			wait := time.Until(sentAt.Add(interval))
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return result, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+	if result.received > 0 {
+		result.avgRTT = totalRTT / time.Duration(result.received)
+	}
+	return result, nil
+}
+
+func pingPeerMatches(peer net.Addr, target net.IP) bool {
+	switch addr := peer.(type) {
+	case *net.UDPAddr:
+		return addr.IP.Equal(target)
+	case *net.IPAddr:
+		return addr.IP.Equal(target)
+	default:
+		return false
+	}
+}
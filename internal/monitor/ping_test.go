@@ -0,0 +1,95 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func skipWithoutICMP(t *testing.T) {
+	t.Helper()
+
+	conn, _, err := listenICMP(false)
+	if err != nil {
+		t.Skipf("icmp sockets unavailable: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestValidatePingSpecLoopback(t *testing.T) {
+	skipWithoutICMP(t)
+
+	maxLoss := 0.0
+	err := validatePingSpec(context.Background(), spec.Spec{
+		Ping: &spec.PingSpec{
+			Name:     "loopback",
+			Host:     "127.0.0.1",
+			Count:    3,
+			Interval: 10 * time.Millisecond,
+			Timeout:  time.Second,
+			Expect: spec.PingExpect{
+				MaxLossPercent: &maxLoss,
+				MaxAvgRTT:      500 * time.Millisecond,
+				MaxRTT:         time.Second,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validatePingSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidatePingSpecRTTExceeded(t *testing.T) {
+	skipWithoutICMP(t)
+
+	err := validatePingSpec(context.Background(), spec.Spec{
+		Ping: &spec.PingSpec{
+			Name:     "loopback",
+			Host:     "127.0.0.1",
+			Count:    1,
+			Interval: 10 * time.Millisecond,
+			Timeout:  time.Second,
+			Expect:   spec.PingExpect{MaxRTT: time.Nanosecond},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "max rtt") {
+		t.Fatalf("validatePingSpec() error = %v, want max rtt failure", err)
+	}
+}
+
+func TestPingResultLossPercent(t *testing.T) {
+	result := pingResult{sent: 4, received: 3}
+	if got := result.lossPercent(); got != 25 {
+		t.Fatalf("lossPercent() = %v, want 25", got)
+	}
+}
+
+func TestNextPingEchoIDIsUniquePerCall(t *testing.T) {
+	const calls = 64
+	ids := make(chan int, calls)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- nextPingEchoID()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool, calls)
+	for id := range ids {
+		if id < 0 || id > 0xffff {
+			t.Fatalf("nextPingEchoID() = %d, want 16-bit value", id)
+		}
+		if seen[id] {
+			t.Fatalf("nextPingEchoID() returned %d twice", id)
+		}
+		seen[id] = true
+	}
+}
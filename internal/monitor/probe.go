@@ -2,11 +2,11 @@ package monitor
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"reflect"
 	"regexp"
@@ -65,8 +65,6 @@ func executeProbeRequest(ctx context.Context, request spec.ProbeRequest) (probeR
 	if reqTimeout <= 0 {
 		reqTimeout = 15 * time.Second
 	}
-	reqCtx, cancel := context.WithTimeout(ctx, reqTimeout)
-	defer cancel()
 
 	targetURL, err := url.Parse(strings.TrimSpace(request.URL))
 	if err != nil {
@@ -84,15 +82,25 @@ func executeProbeRequest(ctx context.Context, request spec.ProbeRequest) (probeR
 		targetURL.RawQuery = query.Encode()
 	}
 
+	body, contentType, err := buildHTTPBody(request.Body, expandProbeTemplate)
+	if err != nil {
+		return probeRequestResult{}, err
+	}
 	method := strings.TrimSpace(request.Method)
 	if method == "" {
 		method = http.MethodGet
+		if body != nil {
+			method = http.MethodPost
+		}
 	}
 
-	req, err := http.NewRequestWithContext(reqCtx, method, targetURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, method, targetURL.String(), body)
 	if err != nil {
 		return probeRequestResult{}, fmt.Errorf("build request: %w", err)
 	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 	for headerName, value := range request.Headers {
 		expandedValue := expandProbeTemplate(value)
 		if strings.EqualFold(headerName, "host") {
@@ -102,11 +110,13 @@ func executeProbeRequest(ctx context.Context, request spec.ProbeRequest) (probeR
 		req.Header.Set(headerName, expandedValue)
 	}
 
-	client := &http.Client{Timeout: reqTimeout}
-	if request.InsecureSkipTLS {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+	transport, err := newHTTPTransport(ctx, request.TLS, request.InsecureSkipTLS, request.Proxy)
+	if err != nil {
+		return probeRequestResult{}, err
+	}
+	client := &http.Client{
+		Timeout:   reqTimeout,
+		Transport: transport,
 	}
 	if !request.FollowRedirects {
 		client.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
@@ -114,17 +124,37 @@ func executeProbeRequest(ctx context.Context, request spec.ProbeRequest) (probeR
 		}
 	}
 
+	reportAuthStatus, err := applyHTTPAuth(ctx, req, request.Auth, client)
+	if err != nil {
+		return probeRequestResult{}, err
+	}
+
+	// Start the deadline and timer after auth so that fetching an OAuth2
+	// token counts neither against the request timeout nor its timings.
+	reqCtx, cancel := context.WithTimeout(ctx, reqTimeout)
+	defer cancel()
+	timer := newRequestTimer(request.ID)
+	req = req.WithContext(httptrace.WithClientTrace(reqCtx, timer.clientTrace()))
+
 	resp, err := client.Do(req)
 	if err != nil {
-		return probeRequestResult{}, fmt.Errorf("perform request: %w", err)
+		recordRequestTiming(ctx, timer.finish())
+		return probeRequestResult{}, fmt.Errorf("perform request: %w", describeTLSError(err))
 	}
 	defer resp.Body.Close()
+	reportAuthStatus(resp.StatusCode)
 
 	bodyBytes, err := io.ReadAll(resp.Body)
+	timing := timer.finish()
+	recordRequestTiming(ctx, timing)
 	if err != nil {
 		return probeRequestResult{}, fmt.Errorf("read response body: %w", err)
 	}
 
+	if err := checkRequestTiming(timing, request.Expect); err != nil {
+		return probeRequestResult{}, err
+	}
+
 	return probeRequestResult{
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header.Clone(),
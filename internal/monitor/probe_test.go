@@ -317,3 +317,65 @@ func TestValidateProbeSpecJSONPathAgeSeconds(t *testing.T) {
 		t.Fatalf("validateProbeSpec() error = %v, want nil", err)
 	}
 }
+
+func TestValidateProbeSpecPostsFormWithTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("user") != "eddie" || r.FormValue("nonce") == "{unix_ts}" {
+			http.Error(w, "unexpected login", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer server.Close()
+
+	err := validateProbeSpec(context.Background(), spec.Spec{
+		Probe: &spec.ProbeSpec{
+			Name: "login",
+			Requests: []spec.ProbeRequest{
+				{
+					ID:   "login",
+					URL:  server.URL + "/login",
+					Body: &spec.HTTPBody{Form: map[string]string{"user": "eddie", "nonce": "{unix_ts}"}},
+				},
+			},
+			Extracts: []spec.ProbeExtract{
+				{ID: "token", From: "login", Source: spec.ProbeSource{Type: "json_path", Key: "$.token"}},
+			},
+			Asserts: []spec.ProbeAssert{
+				{ID: "token", Op: "eq", Left: spec.ProbeOperand{Ref: "token"}, Right: spec.ProbeOperand{Value: "abc"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateProbeSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateProbeSpecRequestMaxDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	ctx, recorder := withTimingRecorder(context.Background())
+	err := validateProbeSpec(ctx, spec.Spec{
+		Probe: &spec.ProbeSpec{
+			Name: "slow",
+			Requests: []spec.ProbeRequest{
+				{ID: "fast", URL: server.URL, Expect: spec.HTTPTimingExpect{MaxDuration: 5 * time.Second}},
+				{ID: "slow", URL: server.URL, Expect: spec.HTTPTimingExpect{MaxDuration: 10 * time.Millisecond}},
+			},
+			Extracts: []spec.ProbeExtract{{ID: "body", From: "fast", Source: spec.ProbeSource{Type: "body"}}},
+			Asserts:  []spec.ProbeAssert{{ID: "ok", Op: "eq", Left: spec.ProbeOperand{Ref: "body"}, Right: spec.ProbeOperand{Value: "ok"}}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `request "slow": request took`) {
+		t.Fatalf("validateProbeSpec() error = %v, want max_duration failure", err)
+	}
+	timings := recorder.Timings()
+	if len(timings) != 2 || timings[0].Label != "fast" || timings[1].Label != "slow" {
+		t.Fatalf("timings = %+v, want fast and slow", timings)
+	}
+}
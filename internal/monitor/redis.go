@@ -0,0 +1,274 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// errRedisNil is returned for RESP null replies.
+var errRedisNil = errors.New("redis: nil reply")
+
+// Replies to INFO, GET, TTL, TYPE and LLEN are small; larger announced
+// lengths point at a misbehaving or wrongly addressed endpoint.
+const (
+	redisMaxBulkLength  = 64 << 20
+	redisMaxArrayLength = 1 << 16
+)
+
+func validateRedisSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	redisSpec := parsedSpec.Redis
+	if redisSpec == nil {
+		return fmt.Errorf("missing redis spec")
+	}
+
+	address := strings.TrimSpace(redisSpec.Address)
+	timeout := redisSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	password, err := resolveSecret("redis.password", redisSpec.Password, redisSpec.PasswordEnv, redisSpec.PasswordFile)
+	if err != nil {
+		return err
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(dialCtx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if redisSpec.TLS != nil {
+		tlsConfig, err := buildClientTLSConfig(*redisSpec.TLS, redisSpec.InsecureSkipTLS)
+		if err != nil {
+			return err
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(dialCtx); err != nil {
+			return fmt.Errorf("tls handshake: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	client := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if password != "" {
+		args := []string{"AUTH", password}
+		if username := strings.TrimSpace(redisSpec.Username); username != "" {
+			args = []string{"AUTH", username, password}
+		}
+		if _, err := client.do(args...); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if redisSpec.DB > 0 {
+		if _, err := client.do("SELECT", strconv.Itoa(redisSpec.DB)); err != nil {
+			return fmt.Errorf("select db %d: %w", redisSpec.DB, err)
+		}
+	}
+
+	pong, err := client.do("PING")
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	if pong != "PONG" {
+		return fmt.Errorf("ping: unexpected reply %q", pong)
+	}
+
+	values := make(map[string]any)
+	if redisAssertsUseInfo(redisSpec.Asserts) {
+		reply, err := client.do("INFO")
+		if err != nil {
+			return fmt.Errorf("info: %w", err)
+		}
+		info, ok := reply.(string)
+		if !ok {
+			return fmt.Errorf("info: unexpected reply %v", reply)
+		}
+		for field, value := range parseRedisInfo(info) {
+			values["info."+field] = value
+		}
+	}
+	for _, key := range redisSpec.Keys {
+		value, err := readRedisKey(client, key)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key.Key, err)
+		}
+		values[strings.TrimSpace(key.ID)] = value
+	}
+
+	for _, assertion := range redisSpec.Asserts {
+		if err := evaluateProbeAssert(assertion, values); err != nil {
+			return fmt.Errorf("assert %q failed: %w", assertion.ID, err)
+		}
+	}
+
+	_, _ = client.do("QUIT")
+	return nil
+}
+
+func redisAssertsUseInfo(asserts []spec.ProbeAssert) bool {
+	for _, assertion := range asserts {
+		operands := append([]spec.ProbeOperand{assertion.Left, assertion.Right}, assertion.Values...)
+		for _, operand := range operands {
+			if strings.HasPrefix(strings.TrimSpace(operand.Ref), "info.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseRedisInfo parses "field:value" lines of an INFO reply, skipping
+// section headers.
+func parseRedisInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields[field] = value
+	}
+	return fields
+}
+
+func readRedisKey(client *redisConn, key spec.RedisKey) (any, error) {
+	switch strings.TrimSpace(key.Measure) {
+	case "value":
+		value, err := client.do("GET", key.Key)
+		if errors.Is(err, errRedisNil) {
+			return nil, fmt.Errorf("does not exist")
+		}
+		return value, err
+	case "ttl":
+		ttl, err := client.do("TTL", key.Key)
+		if err != nil {
+			return nil, err
+		}
+		if ttl == int64(-2) {
+			return nil, fmt.Errorf("does not exist")
+		}
+		return ttl, nil
+	case "length":
+		keyType, err := client.do("TYPE", key.Key)
+		if err != nil {
+			return nil, err
+		}
+		command := map[string]string{
+			"none":   "",
+			"string": "STRLEN",
+			"list":   "LLEN",
+			"set":    "SCARD",
+			"zset":   "ZCARD",
+			"hash":   "HLEN",
+			"stream": "XLEN",
+		}
+		name, ok := command[fmt.Sprint(keyType)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported type %v for length", keyType)
+		}
+		if name == "" {
+			return int64(0), nil
+		}
+		return client.do(name, key.Key)
+	default:
+		return nil, fmt.Errorf("unsupported measure %q", key.Measure)
+	}
+}
+
+// redisConn is a minimal RESP2 client for single request/reply commands.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (c *redisConn) do(args ...string) (any, error) {
+	var command strings.Builder
+	command.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		command.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if _, err := io.WriteString(c.conn, command.String()); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	payload := line[1:]
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, fmt.Errorf("%s", payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", payload)
+		}
+		if size < 0 {
+			return nil, errRedisNil
+		}
+		if size > redisMaxBulkLength {
+			return nil, fmt.Errorf("bulk length %d exceeds limit of %d bytes", size, redisMaxBulkLength)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q", payload)
+		}
+		if count < 0 {
+			return nil, errRedisNil
+		}
+		if count > redisMaxArrayLength {
+			return nil, fmt.Errorf("array length %d exceeds limit of %d items", count, redisMaxArrayLength)
+		}
+		items := make([]any, 0, count)
+		for range count {
+			item, err := c.readReply()
+			if err != nil && !errors.Is(err, errRedisNil) {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
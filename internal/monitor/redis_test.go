@@ -0,0 +1,243 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// startRedisServer serves a fixed dataset over RESP2. It requires AUTH for
+// monitor/secret and only answers the commands the redis check issues.
+func startRedisServer(t *testing.T) (string, int) {
+	t.Helper()
+
+	return startTCPServer(t, func(conn net.Conn) {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+		authenticated := false
+		db := 0
+		for {
+			args, err := readRESPCommand(reader)
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(args[0])
+			if command != "AUTH" && command != "QUIT" && !authenticated {
+				_, _ = io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+				continue
+			}
+			switch command {
+			case "AUTH":
+				if len(args) == 3 && args[1] == "monitor" && args[2] == "secret" {
+					authenticated = true
+					_, _ = io.WriteString(conn, "+OK\r\n")
+				} else {
+					_, _ = io.WriteString(conn, "-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+				}
+			case "SELECT":
+				db, _ = strconv.Atoi(args[1])
+				_, _ = io.WriteString(conn, "+OK\r\n")
+			case "PING":
+				_, _ = io.WriteString(conn, "+PONG\r\n")
+			case "INFO":
+				info := "# Replication\r\nrole:master\r\nconnected_slaves:2\r\n\r\n# Memory\r\nused_memory:1048576\r\n"
+				_, _ = fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(info), info)
+			case "GET":
+				switch {
+				case db == 2 && args[1] == "deploy:version":
+					_, _ = io.WriteString(conn, "$6\r\nv1.4.2\r\n")
+				case args[1] == "oversized:bulk":
+					_, _ = io.WriteString(conn, "$9223372036854775807\r\n")
+				case args[1] == "oversized:array":
+					_, _ = io.WriteString(conn, "*9223372036854775807\r\n")
+				default:
+					_, _ = io.WriteString(conn, "$-1\r\n")
+				}
+			case "TTL":
+				if db == 2 && args[1] == "deploy:version" {
+					_, _ = io.WriteString(conn, ":3600\r\n")
+				} else {
+					_, _ = io.WriteString(conn, ":-2\r\n")
+				}
+			case "TYPE":
+				switch {
+				case db == 2 && args[1] == "queue:jobs":
+					_, _ = io.WriteString(conn, "+list\r\n")
+				case db == 2 && args[1] == "deploy:version":
+					_, _ = io.WriteString(conn, "+string\r\n")
+				default:
+					_, _ = io.WriteString(conn, "+none\r\n")
+				}
+			case "LLEN":
+				_, _ = io.WriteString(conn, ":42\r\n")
+			case "QUIT":
+				_, _ = io.WriteString(conn, "+OK\r\n")
+				return
+			default:
+				_, _ = fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+			}
+		}
+	})
+}
+
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "*")))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("invalid command header %q", header)
+	}
+	args := make([]string, 0, count)
+	for range count {
+		sizeLine, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(sizeLine, "$")))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:size]))
+	}
+	return args, nil
+}
+
+func TestValidateRedisSpecInfoAndKeys(t *testing.T) {
+	host, port := startRedisServer(t)
+
+	err := validateRedisSpec(context.Background(), spec.Spec{
+		Redis: &spec.RedisSpec{
+			Name:     "queue",
+			Address:  net.JoinHostPort(host, strconv.Itoa(port)),
+			Username: "monitor",
+			Password: "secret",
+			DB:       2,
+			Timeout:  2 * time.Second,
+			Keys: []spec.RedisKey{
+				{ID: "jobs", Key: "queue:jobs", Measure: "length"},
+				{ID: "version", Key: "deploy:version", Measure: "value"},
+				{ID: "version_ttl", Key: "deploy:version", Measure: "ttl"},
+				{ID: "missing_len", Key: "queue:missing", Measure: "length"},
+			},
+			Asserts: []spec.ProbeAssert{
+				{ID: "master", Op: "eq", Left: spec.ProbeOperand{Ref: "info.role"}, Right: spec.ProbeOperand{Value: "master"}},
+				{ID: "replicas", Op: "gte", Left: spec.ProbeOperand{Ref: "info.connected_slaves"}, Right: spec.ProbeOperand{Value: 1}},
+				{ID: "backlog", Op: "lte", Left: spec.ProbeOperand{Ref: "jobs"}, Right: spec.ProbeOperand{Value: 100}},
+				{ID: "version", Op: "matches", Left: spec.ProbeOperand{Ref: "version"}, Right: spec.ProbeOperand{Value: `^v1\.`}},
+				{ID: "ttl", Op: "gt", Left: spec.ProbeOperand{Ref: "version_ttl"}, Right: spec.ProbeOperand{Value: 60}},
+				{ID: "empty", Op: "eq", Left: spec.ProbeOperand{Ref: "missing_len"}, Right: spec.ProbeOperand{Value: 0}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateRedisSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateRedisSpecAssertionFailure(t *testing.T) {
+	host, port := startRedisServer(t)
+
+	err := validateRedisSpec(context.Background(), spec.Spec{
+		Redis: &spec.RedisSpec{
+			Name:     "queue",
+			Address:  net.JoinHostPort(host, strconv.Itoa(port)),
+			Username: "monitor",
+			Password: "secret",
+			DB:       2,
+			Timeout:  2 * time.Second,
+			Asserts: []spec.ProbeAssert{
+				{ID: "memory", Op: "lt", Left: spec.ProbeOperand{Ref: "info.used_memory"}, Right: spec.ProbeOperand{Value: 1024}},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `assert "memory" failed`) {
+		t.Fatalf("validateRedisSpec() error = %v, want memory assertion failure", err)
+	}
+}
+
+func TestValidateRedisSpecMissingKey(t *testing.T) {
+	host, port := startRedisServer(t)
+
+	err := validateRedisSpec(context.Background(), spec.Spec{
+		Redis: &spec.RedisSpec{
+			Name:     "queue",
+			Address:  net.JoinHostPort(host, strconv.Itoa(port)),
+			Username: "monitor",
+			Password: "secret",
+			DB:       2,
+			Timeout:  2 * time.Second,
+			Keys:     []spec.RedisKey{{ID: "lock", Key: "cron:lock", Measure: "ttl"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `key "cron:lock": does not exist`) {
+		t.Fatalf("validateRedisSpec() error = %v, want missing key", err)
+	}
+}
+
+func TestValidateRedisSpecAuthFailure(t *testing.T) {
+	host, port := startRedisServer(t)
+
+	err := validateRedisSpec(context.Background(), spec.Spec{
+		Redis: &spec.RedisSpec{
+			Name:     "queue",
+			Address:  net.JoinHostPort(host, strconv.Itoa(port)),
+			Username: "monitor",
+			Password: "wrong",
+			DB:       2,
+			Timeout:  2 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "auth: WRONGPASS") {
+		t.Fatalf("validateRedisSpec() error = %v, want auth failure", err)
+	}
+}
+
+func TestValidateRedisSpecRejectsOversizedBulkLength(t *testing.T) {
+	host, port := startRedisServer(t)
+
+	err := validateRedisSpec(context.Background(), spec.Spec{
+		Redis: &spec.RedisSpec{
+			Name:     "queue",
+			Address:  net.JoinHostPort(host, strconv.Itoa(port)),
+			Username: "monitor",
+			Password: "secret",
+			Timeout:  2 * time.Second,
+			Keys:     []spec.RedisKey{{ID: "blob", Key: "oversized:bulk", Measure: "value"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "bulk length 9223372036854775807 exceeds limit") {
+		t.Fatalf("validateRedisSpec() error = %v, want bulk length limit", err)
+	}
+}
+
+func TestValidateRedisSpecRejectsOversizedArrayLength(t *testing.T) {
+	host, port := startRedisServer(t)
+
+	err := validateRedisSpec(context.Background(), spec.Spec{
+		Redis: &spec.RedisSpec{
+			Name:     "queue",
+			Address:  net.JoinHostPort(host, strconv.Itoa(port)),
+			Username: "monitor",
+			Password: "secret",
+			Timeout:  2 * time.Second,
+			Keys:     []spec.RedisKey{{ID: "blob", Key: "oversized:array", Measure: "value"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "array length 9223372036854775807 exceeds limit") {
+		t.Fatalf("validateRedisSpec() error = %v, want array length limit", err)
+	}
+}
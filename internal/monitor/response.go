@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// checkResponseExpect verifies a received payload against exact/contains/regex
+// expectations. All configured expectations must pass.
+func checkResponseExpect(expect spec.ResponseExpect, response string) error {
+	if expect.Exact != "" && response != expect.Exact {
+		return fmt.Errorf("unexpected response: got %q, want %q", truncateForError(response), expect.Exact)
+	}
+	if expect.Contains != "" && !strings.Contains(response, expect.Contains) {
+		return fmt.Errorf("response %q does not contain %q", truncateForError(response), expect.Contains)
+	}
+	if expect.Regex != "" {
+		matcher, err := regexp.Compile(expect.Regex)
+		if err != nil {
+			return fmt.Errorf("compile regex %q: %w", expect.Regex, err)
+		}
+		if !matcher.MatchString(response) {
+			return fmt.Errorf("response %q does not match %q", truncateForError(response), expect.Regex)
+		}
+	}
+	return nil
+}
+
+func truncateForError(value string) string {
+	const maxLen = 256
+	if len(value) <= maxLen {
+		return value
+	}
+	return value[:maxLen] + "..."
+}
+
+// checkJSONPathExpect compares dotted JSON paths in body with expected values.
+func checkJSONPathExpect(body string, expected map[string]any) error {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+
+	paths := make([]string, 0, len(expected))
+	for path := range expected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		got, err := resolveJSONPath(decoded, path)
+		if err != nil {
+			return err
+		}
+		equal, err := probeValuesEqual(got, expected[path])
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return fmt.Errorf("json_path %q: got %v, want %v", path, got, expected[path])
+		}
+	}
+	return nil
+}
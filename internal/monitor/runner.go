@@ -2,12 +2,13 @@ package monitor
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"io"
 	"log/slog"
 	nethttp "net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os/exec"
 	"strings"
@@ -30,13 +31,18 @@ const (
 
 // Runner executes spec checks in cycles.
 type Runner struct {
-	specs          []spec.Spec
-	cycleInterval  time.Duration
-	startupJitter  time.Duration
-	stateStore     state.Store
-	mailService    *mail.Service
-	mailRecipients []string
-	cycleNumber    uint64
+	specs           []spec.Spec
+	cycleInterval   time.Duration
+	startupJitter   time.Duration
+	stateStore      state.Store
+	mailService     *mail.Service
+	mailRecipients  []string
+	cycleNumber     uint64
+	startedAt       time.Time
+	heartbeatStore  *state.HeartbeatStore
+	heartbeatTokens map[string]spec.Spec
+	oauth2Tokens    *oauth2TokenCache
+	httpProxy       string
 }
 
 // NewRunner creates a monitoring runner.
@@ -47,19 +53,33 @@ func NewRunner(
 	stateStore state.Store,
 	mailService *mail.Service,
 	mailRecipients []string,
+	httpProxy string,
 ) *Runner {
+	heartbeatTokens := make(map[string]spec.Spec)
+	for _, parsedSpec := range specs {
+		if parsedSpec.Heartbeat != nil && parsedSpec.Heartbeat.Token != "" {
+			heartbeatTokens[parsedSpec.Heartbeat.Token] = parsedSpec
+		}
+	}
+
 	return &Runner{
-		specs:          specs,
-		cycleInterval:  cycleInterval,
-		startupJitter:  startupJitter,
-		stateStore:     stateStore,
-		mailService:    mailService,
-		mailRecipients: mailRecipients,
+		specs:           specs,
+		cycleInterval:   cycleInterval,
+		startupJitter:   startupJitter,
+		stateStore:      stateStore,
+		mailService:     mailService,
+		mailRecipients:  mailRecipients,
+		startedAt:       time.Now(),
+		heartbeatStore:  state.NewHeartbeatStore(),
+		heartbeatTokens: heartbeatTokens,
+		oauth2Tokens:    newOAuth2TokenCache(),
+		httpProxy:       httpProxy,
 	}
 }
 
 // Run executes checks immediately and then every cycle interval.
 func (r *Runner) Run(ctx context.Context) {
+	r.startedAt = time.Now()
 	r.runCycle(ctx)
 
 	ticker := time.NewTicker(r.cycleInterval)
@@ -96,8 +116,9 @@ func (r *Runner) runCycle(ctx context.Context) {
 			}
 			cycleStartedAt := time.Now()
 			r.markCycleStarted(parsedSpec, cycleStartedAt)
-			checkErr := validateSpec(ctx, parsedSpec)
-			r.handleCycleResult(parsedSpec, checkErr, cycleStartedAt)
+			checkCtx, timings := withTimingRecorder(withDefaultHTTPProxy(withOAuth2TokenCache(ctx, r.oauth2Tokens), r.httpProxy))
+			checkErr := r.validateSpec(checkCtx, parsedSpec)
+			r.handleCycleResult(parsedSpec, checkErr, timings.Timings(), cycleStartedAt)
 		})
 	}
 	wg.Wait()
@@ -151,7 +172,7 @@ func (r *Runner) markCycleStarted(parsedSpec spec.Spec, cycleStartedAt time.Time
 	r.stateStore.Set(specID, currentState)
 }
 
-func (r *Runner) handleCycleResult(parsedSpec spec.Spec, checkErr error, cycleStartedAt time.Time) {
+func (r *Runner) handleCycleResult(parsedSpec spec.Spec, checkErr error, timings []state.RequestTiming, cycleStartedAt time.Time) {
 	cycles := specCycles(parsedSpec)
 	failureThreshold := thresholdOrDefault(cycles.Failure, 1)
 	successThreshold := thresholdOrDefault(cycles.Success, 1)
@@ -188,6 +209,11 @@ func (r *Runner) handleCycleResult(parsedSpec spec.Spec, checkErr error, cycleSt
 	}
 	nextState.LastCycleStartedAt = cycleStartedAt
 	nextState.LastCycleAt = cycleCompletedAt
+	nextState.LastError = ""
+	if checkErr != nil {
+		nextState.LastError = checkErr.Error()
+	}
+	nextState.Timings = timings
 	r.stateStore.Set(specID, nextState)
 	took := cycleCompletedAt.Sub(cycleStartedAt)
 
@@ -218,7 +244,7 @@ func (r *Runner) handleCycleResult(parsedSpec spec.Spec, checkErr error, cycleSt
 			"source", parsedSpec.SourcePath,
 			"error", checkErr,
 		)
-		r.triggerFailureActions(parsedSpec, checkErr)
+		r.triggerFailureActions(parsedSpec, checkErr, timings)
 	case transitionRecovery:
 		slog.Info("spec_recovered",
 			"name", specName,
@@ -306,6 +332,50 @@ func specCycles(parsedSpec spec.Spec) spec.SpecCycles {
 		return parsedSpec.Probe.Cycles
 	case parsedSpec.S3 != nil:
 		return parsedSpec.S3.Cycles
+	case parsedSpec.DNS != nil:
+		return parsedSpec.DNS.Cycles
+	case parsedSpec.TCP != nil:
+		return parsedSpec.TCP.Cycles
+	case parsedSpec.Exec != nil:
+		return parsedSpec.Exec.Cycles
+	case parsedSpec.Heartbeat != nil:
+		return parsedSpec.Heartbeat.Cycles
+	case parsedSpec.GRPC != nil:
+		return parsedSpec.GRPC.Cycles
+	case parsedSpec.WebSocket != nil:
+		return parsedSpec.WebSocket.Cycles
+	case parsedSpec.SMTP != nil:
+		return parsedSpec.SMTP.Cycles
+	case parsedSpec.MailRoundTrip != nil:
+		return parsedSpec.MailRoundTrip.Cycles
+	case parsedSpec.Postgres != nil:
+		return parsedSpec.Postgres.Cycles
+	case parsedSpec.MySQL != nil:
+		return parsedSpec.MySQL.Cycles
+	case parsedSpec.Redis != nil:
+		return parsedSpec.Redis.Cycles
+	case parsedSpec.Ping != nil:
+		return parsedSpec.Ping.Cycles
+	case parsedSpec.File != nil:
+		return parsedSpec.File.Cycles
+	case parsedSpec.Domain != nil:
+		return parsedSpec.Domain.Cycles
+	case parsedSpec.DNSBL != nil:
+		return parsedSpec.DNSBL.Cycles
+	case parsedSpec.MQTT != nil:
+		return parsedSpec.MQTT.Cycles
+	case parsedSpec.AMQP != nil:
+		return parsedSpec.AMQP.Cycles
+	case parsedSpec.SSH != nil:
+		return parsedSpec.SSH.Cycles
+	case parsedSpec.SFTP != nil:
+		return parsedSpec.SFTP.Cycles
+	case parsedSpec.LDAP != nil:
+		return parsedSpec.LDAP.Cycles
+	case parsedSpec.NTP != nil:
+		return parsedSpec.NTP.Cycles
+	case parsedSpec.UDP != nil:
+		return parsedSpec.UDP.Cycles
 	default:
 		return spec.SpecCycles{}
 	}
@@ -321,6 +391,50 @@ func specEveryCycles(parsedSpec spec.Spec) int {
 		return parsedSpec.Probe.EveryCycles
 	case parsedSpec.S3 != nil:
 		return parsedSpec.S3.EveryCycles
+	case parsedSpec.DNS != nil:
+		return parsedSpec.DNS.EveryCycles
+	case parsedSpec.TCP != nil:
+		return parsedSpec.TCP.EveryCycles
+	case parsedSpec.Exec != nil:
+		return parsedSpec.Exec.EveryCycles
+	case parsedSpec.Heartbeat != nil:
+		return parsedSpec.Heartbeat.EveryCycles
+	case parsedSpec.GRPC != nil:
+		return parsedSpec.GRPC.EveryCycles
+	case parsedSpec.WebSocket != nil:
+		return parsedSpec.WebSocket.EveryCycles
+	case parsedSpec.SMTP != nil:
+		return parsedSpec.SMTP.EveryCycles
+	case parsedSpec.MailRoundTrip != nil:
+		return parsedSpec.MailRoundTrip.EveryCycles
+	case parsedSpec.Postgres != nil:
+		return parsedSpec.Postgres.EveryCycles
+	case parsedSpec.MySQL != nil:
+		return parsedSpec.MySQL.EveryCycles
+	case parsedSpec.Redis != nil:
+		return parsedSpec.Redis.EveryCycles
+	case parsedSpec.Ping != nil:
+		return parsedSpec.Ping.EveryCycles
+	case parsedSpec.File != nil:
+		return parsedSpec.File.EveryCycles
+	case parsedSpec.Domain != nil:
+		return parsedSpec.Domain.EveryCycles
+	case parsedSpec.DNSBL != nil:
+		return parsedSpec.DNSBL.EveryCycles
+	case parsedSpec.MQTT != nil:
+		return parsedSpec.MQTT.EveryCycles
+	case parsedSpec.AMQP != nil:
+		return parsedSpec.AMQP.EveryCycles
+	case parsedSpec.SSH != nil:
+		return parsedSpec.SSH.EveryCycles
+	case parsedSpec.SFTP != nil:
+		return parsedSpec.SFTP.EveryCycles
+	case parsedSpec.LDAP != nil:
+		return parsedSpec.LDAP.EveryCycles
+	case parsedSpec.NTP != nil:
+		return parsedSpec.NTP.EveryCycles
+	case parsedSpec.UDP != nil:
+		return parsedSpec.UDP.EveryCycles
 	default:
 		return 0
 	}
@@ -336,6 +450,50 @@ func specOnFailure(parsedSpec spec.Spec) string {
 		return parsedSpec.Probe.OnFailure
 	case parsedSpec.S3 != nil:
 		return parsedSpec.S3.OnFailure
+	case parsedSpec.DNS != nil:
+		return parsedSpec.DNS.OnFailure
+	case parsedSpec.TCP != nil:
+		return parsedSpec.TCP.OnFailure
+	case parsedSpec.Exec != nil:
+		return parsedSpec.Exec.OnFailure
+	case parsedSpec.Heartbeat != nil:
+		return parsedSpec.Heartbeat.OnFailure
+	case parsedSpec.GRPC != nil:
+		return parsedSpec.GRPC.OnFailure
+	case parsedSpec.WebSocket != nil:
+		return parsedSpec.WebSocket.OnFailure
+	case parsedSpec.SMTP != nil:
+		return parsedSpec.SMTP.OnFailure
+	case parsedSpec.MailRoundTrip != nil:
+		return parsedSpec.MailRoundTrip.OnFailure
+	case parsedSpec.Postgres != nil:
+		return parsedSpec.Postgres.OnFailure
+	case parsedSpec.MySQL != nil:
+		return parsedSpec.MySQL.OnFailure
+	case parsedSpec.Redis != nil:
+		return parsedSpec.Redis.OnFailure
+	case parsedSpec.Ping != nil:
+		return parsedSpec.Ping.OnFailure
+	case parsedSpec.File != nil:
+		return parsedSpec.File.OnFailure
+	case parsedSpec.Domain != nil:
+		return parsedSpec.Domain.OnFailure
+	case parsedSpec.DNSBL != nil:
+		return parsedSpec.DNSBL.OnFailure
+	case parsedSpec.MQTT != nil:
+		return parsedSpec.MQTT.OnFailure
+	case parsedSpec.AMQP != nil:
+		return parsedSpec.AMQP.OnFailure
+	case parsedSpec.SSH != nil:
+		return parsedSpec.SSH.OnFailure
+	case parsedSpec.SFTP != nil:
+		return parsedSpec.SFTP.OnFailure
+	case parsedSpec.LDAP != nil:
+		return parsedSpec.LDAP.OnFailure
+	case parsedSpec.NTP != nil:
+		return parsedSpec.NTP.OnFailure
+	case parsedSpec.UDP != nil:
+		return parsedSpec.UDP.OnFailure
 	default:
 		return ""
 	}
@@ -351,6 +509,50 @@ func specOnResolved(parsedSpec spec.Spec) string {
 		return parsedSpec.Probe.OnResolved
 	case parsedSpec.S3 != nil:
 		return parsedSpec.S3.OnResolved
+	case parsedSpec.DNS != nil:
+		return parsedSpec.DNS.OnResolved
+	case parsedSpec.TCP != nil:
+		return parsedSpec.TCP.OnResolved
+	case parsedSpec.Exec != nil:
+		return parsedSpec.Exec.OnResolved
+	case parsedSpec.Heartbeat != nil:
+		return parsedSpec.Heartbeat.OnResolved
+	case parsedSpec.GRPC != nil:
+		return parsedSpec.GRPC.OnResolved
+	case parsedSpec.WebSocket != nil:
+		return parsedSpec.WebSocket.OnResolved
+	case parsedSpec.SMTP != nil:
+		return parsedSpec.SMTP.OnResolved
+	case parsedSpec.MailRoundTrip != nil:
+		return parsedSpec.MailRoundTrip.OnResolved
+	case parsedSpec.Postgres != nil:
+		return parsedSpec.Postgres.OnResolved
+	case parsedSpec.MySQL != nil:
+		return parsedSpec.MySQL.OnResolved
+	case parsedSpec.Redis != nil:
+		return parsedSpec.Redis.OnResolved
+	case parsedSpec.Ping != nil:
+		return parsedSpec.Ping.OnResolved
+	case parsedSpec.File != nil:
+		return parsedSpec.File.OnResolved
+	case parsedSpec.Domain != nil:
+		return parsedSpec.Domain.OnResolved
+	case parsedSpec.DNSBL != nil:
+		return parsedSpec.DNSBL.OnResolved
+	case parsedSpec.MQTT != nil:
+		return parsedSpec.MQTT.OnResolved
+	case parsedSpec.AMQP != nil:
+		return parsedSpec.AMQP.OnResolved
+	case parsedSpec.SSH != nil:
+		return parsedSpec.SSH.OnResolved
+	case parsedSpec.SFTP != nil:
+		return parsedSpec.SFTP.OnResolved
+	case parsedSpec.LDAP != nil:
+		return parsedSpec.LDAP.OnResolved
+	case parsedSpec.NTP != nil:
+		return parsedSpec.NTP.OnResolved
+	case parsedSpec.UDP != nil:
+		return parsedSpec.UDP.OnResolved
 	default:
 		return ""
 	}
@@ -366,12 +568,56 @@ func specMailReceivers(parsedSpec spec.Spec) []string {
 		return parsedSpec.Probe.MailReceivers
 	case parsedSpec.S3 != nil:
 		return parsedSpec.S3.MailReceivers
+	case parsedSpec.DNS != nil:
+		return parsedSpec.DNS.MailReceivers
+	case parsedSpec.TCP != nil:
+		return parsedSpec.TCP.MailReceivers
+	case parsedSpec.Exec != nil:
+		return parsedSpec.Exec.MailReceivers
+	case parsedSpec.Heartbeat != nil:
+		return parsedSpec.Heartbeat.MailReceivers
+	case parsedSpec.GRPC != nil:
+		return parsedSpec.GRPC.MailReceivers
+	case parsedSpec.WebSocket != nil:
+		return parsedSpec.WebSocket.MailReceivers
+	case parsedSpec.SMTP != nil:
+		return parsedSpec.SMTP.MailReceivers
+	case parsedSpec.MailRoundTrip != nil:
+		return parsedSpec.MailRoundTrip.MailReceivers
+	case parsedSpec.Postgres != nil:
+		return parsedSpec.Postgres.MailReceivers
+	case parsedSpec.MySQL != nil:
+		return parsedSpec.MySQL.MailReceivers
+	case parsedSpec.Redis != nil:
+		return parsedSpec.Redis.MailReceivers
+	case parsedSpec.Ping != nil:
+		return parsedSpec.Ping.MailReceivers
+	case parsedSpec.File != nil:
+		return parsedSpec.File.MailReceivers
+	case parsedSpec.Domain != nil:
+		return parsedSpec.Domain.MailReceivers
+	case parsedSpec.DNSBL != nil:
+		return parsedSpec.DNSBL.MailReceivers
+	case parsedSpec.MQTT != nil:
+		return parsedSpec.MQTT.MailReceivers
+	case parsedSpec.AMQP != nil:
+		return parsedSpec.AMQP.MailReceivers
+	case parsedSpec.SSH != nil:
+		return parsedSpec.SSH.MailReceivers
+	case parsedSpec.SFTP != nil:
+		return parsedSpec.SFTP.MailReceivers
+	case parsedSpec.LDAP != nil:
+		return parsedSpec.LDAP.MailReceivers
+	case parsedSpec.NTP != nil:
+		return parsedSpec.NTP.MailReceivers
+	case parsedSpec.UDP != nil:
+		return parsedSpec.UDP.MailReceivers
 	default:
 		return nil
 	}
 }
 
-func validateSpec(ctx context.Context, parsedSpec spec.Spec) error {
+func (r *Runner) validateSpec(ctx context.Context, parsedSpec spec.Spec) error {
 	switch parsedSpec.Kind() {
 	case "http":
 		return validateHTTPSpec(ctx, parsedSpec)
@@ -381,6 +627,50 @@ func validateSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		return validateProbeSpec(ctx, parsedSpec)
 	case "s3":
 		return validateS3Spec(ctx, parsedSpec)
+	case "dns":
+		return validateDNSSpec(ctx, parsedSpec)
+	case "tcp":
+		return validateTCPSpec(ctx, parsedSpec)
+	case "exec":
+		return validateExecSpec(ctx, parsedSpec)
+	case "heartbeat":
+		return r.validateHeartbeatSpec(parsedSpec, time.Now())
+	case "grpc":
+		return validateGRPCSpec(ctx, parsedSpec)
+	case "websocket":
+		return validateWebSocketSpec(ctx, parsedSpec)
+	case "smtp":
+		return validateSMTPSpec(ctx, parsedSpec)
+	case "mail_roundtrip":
+		return r.validateMailRoundTripSpec(ctx, parsedSpec)
+	case "postgres":
+		return validatePostgresSpec(ctx, parsedSpec)
+	case "mysql":
+		return validateMySQLSpec(ctx, parsedSpec)
+	case "redis":
+		return validateRedisSpec(ctx, parsedSpec)
+	case "ping":
+		return validatePingSpec(ctx, parsedSpec)
+	case "file":
+		return validateFileSpec(ctx, parsedSpec)
+	case "domain":
+		return validateDomainSpec(ctx, parsedSpec)
+	case "dnsbl":
+		return validateDNSBLSpec(ctx, parsedSpec)
+	case "mqtt":
+		return validateMQTTSpec(ctx, parsedSpec)
+	case "amqp":
+		return validateAMQPSpec(ctx, parsedSpec)
+	case "ssh":
+		return validateSSHSpec(ctx, parsedSpec)
+	case "sftp":
+		return validateSFTPSpec(ctx, parsedSpec)
+	case "ldap":
+		return validateLDAPSpec(ctx, parsedSpec)
+	case "ntp":
+		return validateNTPSpec(ctx, parsedSpec)
+	case "udp":
+		return validateUDPSpec(ctx, parsedSpec)
 	default:
 		return fmt.Errorf("unknown spec type")
 	}
@@ -392,9 +682,6 @@ func validateHTTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		reqTimeout = 15 * time.Second
 	}
 
-	reqCtx, cancel := context.WithTimeout(ctx, reqTimeout)
-	defer cancel()
-
 	targetURL, err := url.Parse(parsedSpec.HTTP.URL)
 	if err != nil {
 		return fmt.Errorf("parse url: %w", err)
@@ -411,15 +698,25 @@ func validateHTTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		targetURL.RawQuery = query.Encode()
 	}
 
+	body, contentType, err := buildHTTPBody(parsedSpec.HTTP.Body, nil)
+	if err != nil {
+		return err
+	}
 	method := strings.TrimSpace(parsedSpec.HTTP.Method)
 	if method == "" {
 		method = nethttp.MethodGet
+		if body != nil {
+			method = nethttp.MethodPost
+		}
 	}
 
-	req, err := nethttp.NewRequestWithContext(reqCtx, method, targetURL.String(), nil)
+	req, err := nethttp.NewRequestWithContext(ctx, method, targetURL.String(), body)
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
 	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 	for headerName, value := range parsedSpec.HTTP.Headers {
 		if strings.EqualFold(headerName, "host") {
 			req.Host = value
@@ -428,11 +725,13 @@ func validateHTTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		req.Header.Set(headerName, value)
 	}
 
-	client := &nethttp.Client{Timeout: reqTimeout}
-	if parsedSpec.HTTP.InsecureSkipTLS {
-		client.Transport = &nethttp.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+	transport, err := newHTTPTransport(ctx, parsedSpec.HTTP.TLS, parsedSpec.HTTP.InsecureSkipTLS, parsedSpec.HTTP.Proxy)
+	if err != nil {
+		return err
+	}
+	client := &nethttp.Client{
+		Timeout:   reqTimeout,
+		Transport: transport,
 	}
 	if !parsedSpec.HTTP.FollowRedirects {
 		client.CheckRedirect = func(_ *nethttp.Request, _ []*nethttp.Request) error {
@@ -440,13 +739,29 @@ func validateHTTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		}
 	}
 
+	reportAuthStatus, err := applyHTTPAuth(ctx, req, parsedSpec.HTTP.Auth, client)
+	if err != nil {
+		return err
+	}
+
+	// Start the deadline and timer after auth so that fetching an OAuth2
+	// token counts neither against the request timeout nor its timings.
+	reqCtx, cancel := context.WithTimeout(ctx, reqTimeout)
+	defer cancel()
+	timer := newRequestTimer("")
+	req = req.WithContext(httptrace.WithClientTrace(reqCtx, timer.clientTrace()))
+
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("perform request: %w", err)
+		recordRequestTiming(ctx, timer.finish())
+		return fmt.Errorf("perform request: %w", describeTLSError(err))
 	}
 	defer resp.Body.Close()
+	reportAuthStatus(resp.StatusCode)
 
 	bodyBytes, err := io.ReadAll(resp.Body)
+	timing := timer.finish()
+	recordRequestTiming(ctx, timing)
 	if err != nil {
 		return fmt.Errorf("read response body: %w", err)
 	}
@@ -479,7 +794,7 @@ func validateHTTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		return fmt.Errorf("response body does not contain %q", parsedSpec.HTTP.Expect.Body.Contains)
 	}
 
-	return nil
+	return checkRequestTiming(timing, parsedSpec.HTTP.Expect.HTTPTimingExpect)
 }
 
 func containsStatusCode(codes []int, statusCode int) bool {
@@ -491,7 +806,7 @@ func containsStatusCode(codes []int, statusCode int) bool {
 	return false
 }
 
-func (r *Runner) triggerFailureActions(parsedSpec spec.Spec, failureErr error) {
+func (r *Runner) triggerFailureActions(parsedSpec spec.Spec, failureErr error, timings []state.RequestTiming) {
 	onFailure := specOnFailure(parsedSpec)
 	specName := parsedSpec.Name()
 	specType := parsedSpec.Kind()
@@ -505,12 +820,7 @@ func (r *Runner) triggerFailureActions(parsedSpec spec.Spec, failureErr error) {
 		return
 	}
 	subject := fmt.Sprintf("eddie failure: %s", specID)
-	body := fmt.Sprintf(
-		"spec failed: %s\r\nsource: %s\r\nreason: %v\r\n",
-		specID,
-		parsedSpec.SourcePath,
-		failureErr,
-	)
+	body := failureMailBody(parsedSpec, failureErr, timings)
 	r.sendEmailToRecipients(subject, body, recipients)
 	slog.Debug("spec_failure_notification",
 		"name", specName,
@@ -520,6 +830,35 @@ func (r *Runner) triggerFailureActions(parsedSpec spec.Spec, failureErr error) {
 	)
 }
 
+// detailedError is implemented by check errors that carry additional output
+// (for example plugin stdout) worth including in failure notifications.
+type detailedError interface {
+	error
+	Details() string
+}
+
+func failureMailBody(parsedSpec spec.Spec, failureErr error, timings []state.RequestTiming) string {
+	body := fmt.Sprintf(
+		"spec failed: %s\r\nsource: %s\r\nreason: %v\r\n",
+		parsedSpec.ID(),
+		parsedSpec.SourcePath,
+		failureErr,
+	)
+	var detailed detailedError
+	if errors.As(failureErr, &detailed) {
+		if details := strings.TrimSpace(detailed.Details()); details != "" {
+			body += "\r\n" + strings.ReplaceAll(details, "\n", "\r\n") + "\r\n"
+		}
+	}
+	if len(timings) > 0 {
+		body += "\r\ntimings:\r\n"
+		for _, timing := range timings {
+			body += "  " + timing.String() + "\r\n"
+		}
+	}
+	return body
+}
+
 func (r *Runner) triggerRecoveryActions(parsedSpec spec.Spec) {
 	onResolved := specOnResolved(parsedSpec)
 	specName := parsedSpec.Name()
@@ -34,7 +34,7 @@ func validateS3Spec(ctx context.Context, parsedSpec spec.Spec) error {
 		return err
 	}
 
-	prefix := expandS3Template(s3Spec.List.Prefix)
+	prefix := expandTimeTemplate(s3Spec.List.Prefix)
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(strings.TrimSpace(s3Spec.List.Bucket)),
 		Prefix: aws.String(prefix),
@@ -108,7 +108,9 @@ func newS3Client(ctx context.Context, s3Spec *spec.S3Spec) (*s3.Client, error) {
 	return s3.NewFromConfig(awsConfig, clientOptions...), nil
 }
 
-func expandS3Template(raw string) string {
+// expandTimeTemplate replaces {utc_hour} and {utc_hour_minus_1} with UTC hours
+// formatted as 2006-01-02-15.
+func expandTimeTemplate(raw string) string {
 	replaced := raw
 	utcNow := time.Now().UTC()
 	replaced = strings.ReplaceAll(replaced, "{utc_hour_minus_1}", utcNow.Add(-1*time.Hour).Format("2006-01-02-15"))
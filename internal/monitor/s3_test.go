@@ -85,14 +85,14 @@ func TestValidateS3SpecListCountGTFailure(t *testing.T) {
 	}
 }
 
-func TestExpandS3Template(t *testing.T) {
-	got := expandS3Template("prefix-{utc_hour_minus_1}-{utc_hour}")
+func TestExpandTimeTemplate(t *testing.T) {
+	got := expandTimeTemplate("prefix-{utc_hour_minus_1}-{utc_hour}")
 	if !strings.Contains(got, "prefix-") {
-		t.Fatalf("expandS3Template() = %q, want prefixed output", got)
+		t.Fatalf("expandTimeTemplate() = %q, want prefixed output", got)
 	}
 	parts := strings.Split(got, "-")
 	if len(parts) < 2 {
-		t.Fatalf("expandS3Template() = %q, want expanded values", got)
+		t.Fatalf("expandTimeTemplate() = %q, want expanded values", got)
 	}
 }
 
@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// resolveSecret returns the inline value, or reads the secret from the named
+// environment variable or file. Trailing newlines are stripped from files.
+func resolveSecret(field, value, envName, filePath string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	if name := strings.TrimSpace(envName); name != "" {
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("%s_env: environment variable %q is not set", field, name)
+		}
+		return secret, nil
+	}
+	if path := strings.TrimSpace(filePath); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s_file: %w", field, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/pkg/sftp"
+)
+
+func validateSFTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	sftpSpec := parsedSpec.SFTP
+	if sftpSpec == nil {
+		return fmt.Errorf("missing sftp spec")
+	}
+	if sftpSpec.List == nil {
+		return fmt.Errorf("missing sftp.list")
+	}
+
+	client, err := dialSSH(ctx, *sftpSpec)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return fmt.Errorf("sftp requires auth")
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return fmt.Errorf("start sftp: %w", err)
+	}
+	defer sftpClient.Close()
+
+	dir := expandTimeTemplate(strings.TrimSpace(sftpSpec.List.Path))
+	files, err := sftpClient.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", dir, err)
+	}
+
+	pattern := expandTimeTemplate(sftpSpec.List.Pattern)
+	count := 0
+	var newest time.Time
+	for _, file := range files {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if pattern != "" {
+			if matched, _ := path.Match(pattern, file.Name()); !matched {
+				continue
+			}
+		}
+		count++
+		if file.ModTime().After(newest) {
+			newest = file.ModTime()
+		}
+	}
+
+	expect := sftpSpec.List.Expect
+	if expect.CountGT != nil && !(count > *expect.CountGT) {
+		return fmt.Errorf("unexpected file count: got %d, want > %d", count, *expect.CountGT)
+	}
+	if expect.CountGTE != nil && !(count >= *expect.CountGTE) {
+		return fmt.Errorf("unexpected file count: got %d, want >= %d", count, *expect.CountGTE)
+	}
+	if expect.CountEQ != nil && count != *expect.CountEQ {
+		return fmt.Errorf("unexpected file count: got %d, want == %d", count, *expect.CountEQ)
+	}
+	if expect.MaxAge > 0 {
+		if count == 0 {
+			return fmt.Errorf("no files to check max_age against")
+		}
+		if age := time.Since(newest); age > expect.MaxAge {
+			return fmt.Errorf("newest file is %s old, want <= %s", age.Round(time.Second), expect.MaxAge)
+		}
+	}
+	return nil
+}
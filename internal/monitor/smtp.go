@@ -0,0 +1,269 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func validateSMTPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	smtpSpec := parsedSpec.SMTP
+	if smtpSpec == nil {
+		return fmt.Errorf("missing smtp spec")
+	}
+
+	host := strings.TrimSpace(smtpSpec.Host)
+	if host == "" {
+		return fmt.Errorf("smtp.host is required")
+	}
+	port := smtpSpec.Port
+	if port <= 0 {
+		port = 25
+		if smtpSpec.ImplicitTLS {
+			port = 465
+		}
+	}
+	timeout := smtpSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	heloName := strings.TrimSpace(smtpSpec.HeloName)
+	if heloName == "" {
+		heloName = "localhost"
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(dialCtx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: smtpSpec.InsecureSkipTLS,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	var peerCertificate *x509.Certificate
+	var transport net.Conn = conn
+	if smtpSpec.ImplicitTLS {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(dialCtx); err != nil {
+			return fmt.Errorf("implicit tls handshake: %w", err)
+		}
+		peerCertificate = firstPeerCertificate(tlsConn.ConnectionState())
+		transport = tlsConn
+	}
+
+	recorder := &greetingRecorder{Conn: transport, recording: true}
+	client, err := smtp.NewClient(recorder, host)
+	recorder.recording = false
+	if err != nil {
+		return fmt.Errorf("greeting: %w", err)
+	}
+	defer client.Close()
+
+	banner := parseSMTPGreeting(recorder.greeting.String())
+	if err := checkResponseExpect(smtpSpec.Expect.Banner, banner); err != nil {
+		return fmt.Errorf("banner: %w", err)
+	}
+
+	if err := client.Hello(heloName); err != nil {
+		return fmt.Errorf("ehlo: %w", err)
+	}
+	// Servers stop advertising STARTTLS after the upgrade, so it is taken
+	// from the first EHLO; all other extensions from the last one.
+	startTLSAdvertised, _ := client.Extension("STARTTLS")
+
+	if smtpSpec.StartTLS {
+		if !startTLSAdvertised {
+			return fmt.Errorf("server does not advertise STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+		if state, ok := client.TLSConnectionState(); ok {
+			peerCertificate = firstPeerCertificate(state)
+		}
+	}
+
+	if smtpSpec.CertMinDaysValid != nil {
+		if peerCertificate == nil {
+			return fmt.Errorf("no peer certificate presented")
+		}
+		if err := checkCertMinDaysValid(peerCertificate, *smtpSpec.CertMinDaysValid); err != nil {
+			return err
+		}
+	}
+
+	if err := checkSMTPExtensions(client, smtpSpec.Expect, startTLSAdvertised); err != nil {
+		return err
+	}
+
+	if smtpSpec.Auth != nil {
+		auth, err := buildSMTPAuth(*smtpSpec.Auth, host)
+		if err != nil {
+			return err
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+	return nil
+}
+
+func checkSMTPExtensions(client *smtp.Client, expect spec.SMTPExpect, startTLSAdvertised bool) error {
+	for _, extension := range expect.Extensions {
+		name := strings.ToUpper(strings.TrimSpace(extension))
+		if name == "STARTTLS" {
+			if !startTLSAdvertised {
+				return fmt.Errorf("server does not advertise STARTTLS")
+			}
+			continue
+		}
+		if ok, _ := client.Extension(name); !ok {
+			return fmt.Errorf("server does not advertise %s", name)
+		}
+	}
+
+	if len(expect.AuthMechanisms) > 0 {
+		ok, params := client.Extension("AUTH")
+		if !ok {
+			return fmt.Errorf("server does not advertise AUTH")
+		}
+		advertised := strings.Fields(strings.ToUpper(params))
+		for _, mechanism := range expect.AuthMechanisms {
+			name := strings.ToUpper(strings.TrimSpace(mechanism))
+			if !containsString(advertised, name) {
+				return fmt.Errorf("server does not advertise AUTH %s (got %v)", name, advertised)
+			}
+		}
+	}
+
+	if expect.MinSize != nil {
+		ok, params := client.Extension("SIZE")
+		if !ok {
+			return fmt.Errorf("server does not advertise SIZE")
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(params), 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse SIZE %q: %w", params, err)
+		}
+		// SIZE 0 means no fixed limit (RFC 1870).
+		if size != 0 && size < *expect.MinSize {
+			return fmt.Errorf("SIZE %d is below %d", size, *expect.MinSize)
+		}
+	}
+
+	return nil
+}
+
+func buildSMTPAuth(authSpec spec.SMTPAuth, host string) (smtp.Auth, error) {
+	password, err := resolveSecret("smtp.auth.password", authSpec.Password, authSpec.PasswordEnv, authSpec.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	username := strings.TrimSpace(authSpec.Username)
+
+	switch strings.ToUpper(strings.TrimSpace(authSpec.Mechanism)) {
+	case "", "PLAIN":
+		return smtp.PlainAuth("", username, password, host), nil
+	case "LOGIN":
+		return &smtpLoginAuth{username: username, password: password, host: host}, nil
+	case "CRAM-MD5":
+		return smtp.CRAMMD5Auth(username, password), nil
+	default:
+		return nil, fmt.Errorf("unsupported smtp.auth.mechanism %q", authSpec.Mechanism)
+	}
+}
+
+// smtpLoginAuth implements the LOGIN mechanism. Like smtp.PlainAuth it
+// refuses to send credentials over unencrypted non-local connections.
+type smtpLoginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *smtpLoginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *smtpLoginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN prompt %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// greetingRecorder captures the bytes read while smtp.NewClient consumes the
+// server greeting, since net/smtp does not expose the banner text.
+type greetingRecorder struct {
+	net.Conn
+	recording bool
+	greeting  bytes.Buffer
+}
+
+func (r *greetingRecorder) Read(p []byte) (int, error) {
+	n, err := r.Conn.Read(p)
+	if r.recording && n > 0 {
+		r.greeting.Write(p[:n])
+	}
+	return n, err
+}
+
+// parseSMTPGreeting strips reply codes from a (possibly multi-line) greeting.
+func parseSMTPGreeting(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	text := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if len(line) < 4 {
+			continue
+		}
+		text = append(text, line[4:])
+	}
+	return strings.Join(text, "\n")
+}
+
+func firstPeerCertificate(state tls.ConnectionState) *x509.Certificate {
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return state.PeerCertificates[0]
+}
@@ -0,0 +1,249 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// startSMTPServer runs a minimal ESMTP server that supports STARTTLS and
+// AUTH PLAIN/LOGIN for user "alice" with password "secret".
+func startSMTPServer(t *testing.T, certificate tls.Certificate) (string, int) {
+	t.Helper()
+
+	return startTCPServer(t, func(conn net.Conn) {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+		writer := conn
+		secure := false
+		reply := func(lines ...string) {
+			for _, line := range lines {
+				_, _ = writer.Write([]byte(line + "\r\n"))
+			}
+		}
+		readLine := func() (string, bool) {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return "", false
+			}
+			return strings.TrimRight(line, "\r\n"), true
+		}
+
+		reply("220-mx.example.test ESMTP ready", "220 no UCE")
+		for {
+			line, ok := readLine()
+			if !ok {
+				return
+			}
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				if secure {
+					reply("250-mx.example.test", "250-SIZE 10485760", "250-8BITMIME", "250 AUTH PLAIN LOGIN")
+				} else {
+					reply("250-mx.example.test", "250-SIZE 10485760", "250-8BITMIME", "250 STARTTLS")
+				}
+			case command == "STARTTLS":
+				reply("220 ready to start TLS")
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{certificate}})
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+				reader = bufio.NewReader(tlsConn)
+				writer = tlsConn
+				secure = true
+			case command == "AUTH PLAIN" || strings.HasPrefix(command, "AUTH PLAIN "):
+				credentials := strings.TrimSpace(line[len("AUTH PLAIN"):])
+				if credentials == "" {
+					reply("334 ")
+					credentials, _ = readLine()
+				}
+				decoded, _ := base64.StdEncoding.DecodeString(credentials)
+				if string(decoded) == "\x00alice\x00secret" {
+					reply("235 2.7.0 Authentication successful")
+				} else {
+					reply("535 5.7.8 Authentication credentials invalid")
+				}
+			case command == "AUTH LOGIN":
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				username, _ := readLine()
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				password, _ := readLine()
+				decodedUser, _ := base64.StdEncoding.DecodeString(username)
+				decodedPass, _ := base64.StdEncoding.DecodeString(password)
+				if string(decodedUser) == "alice" && string(decodedPass) == "secret" {
+					reply("235 2.7.0 Authentication successful")
+				} else {
+					reply("535 5.7.8 Authentication credentials invalid")
+				}
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 command not implemented")
+			}
+		}
+	})
+}
+
+func TestValidateSMTPSpecStartTLSAndAuthPlain(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(90*24*time.Hour)))
+	minDays := 30
+	minSize := int64(10 * 1024 * 1024)
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:             "mx",
+			Host:             host,
+			Port:             port,
+			StartTLS:         true,
+			InsecureSkipTLS:  true,
+			CertMinDaysValid: &minDays,
+			Timeout:          2 * time.Second,
+			Auth:             &spec.SMTPAuth{Mechanism: "PLAIN", Username: "alice", Password: "secret"},
+			Expect: spec.SMTPExpect{
+				Banner:         spec.ResponseExpect{Contains: "ESMTP"},
+				Extensions:     []string{"8BITMIME", "size"},
+				AuthMechanisms: []string{"plain", "LOGIN"},
+				MinSize:        &minSize,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateSMTPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSMTPSpecStartTLSAndAuthLogin(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(90*24*time.Hour)))
+	minDays := 30
+	minSize := int64(10 * 1024 * 1024)
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:             "mx",
+			Host:             host,
+			Port:             port,
+			StartTLS:         true,
+			InsecureSkipTLS:  true,
+			CertMinDaysValid: &minDays,
+			Timeout:          2 * time.Second,
+			Auth:             &spec.SMTPAuth{Mechanism: "LOGIN", Username: "alice", Password: "secret"},
+			Expect: spec.SMTPExpect{
+				Banner:         spec.ResponseExpect{Contains: "ESMTP"},
+				Extensions:     []string{"8BITMIME", "size"},
+				AuthMechanisms: []string{"plain", "LOGIN"},
+				MinSize:        &minSize,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateSMTPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSMTPSpecExpectsStartTLSExtension(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(90*24*time.Hour)))
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:            "mx",
+			Host:            host,
+			Port:            port,
+			StartTLS:        true,
+			InsecureSkipTLS: true,
+			Timeout:         2 * time.Second,
+			Expect:          spec.SMTPExpect{Extensions: []string{"STARTTLS", "AUTH"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateSMTPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSMTPSpecBannerMismatch(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(90*24*time.Hour)))
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:    "mx",
+			Host:    host,
+			Port:    port,
+			Timeout: 2 * time.Second,
+			Expect:  spec.SMTPExpect{Banner: spec.ResponseExpect{Regex: "^postfix"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "banner:") {
+		t.Fatalf("validateSMTPSpec() error = %v, want banner mismatch", err)
+	}
+}
+
+func TestValidateSMTPSpecAuthMechanismsRequireStartTLS(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(90*24*time.Hour)))
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:    "mx",
+			Host:    host,
+			Port:    port,
+			Timeout: 2 * time.Second,
+			Expect:  spec.SMTPExpect{AuthMechanisms: []string{"PLAIN"}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not advertise AUTH") {
+		t.Fatalf("validateSMTPSpec() error = %v, want missing AUTH", err)
+	}
+}
+
+func TestValidateSMTPSpecCertificateExpiresSoon(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(5*24*time.Hour)))
+	minDays := 14
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:             "mx",
+			Host:             host,
+			Port:             port,
+			StartTLS:         true,
+			InsecureSkipTLS:  true,
+			CertMinDaysValid: &minDays,
+			Timeout:          2 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "certificate expires too soon") {
+		t.Fatalf("validateSMTPSpec() error = %v, want certificate expiry failure", err)
+	}
+}
+
+func TestValidateSMTPSpecWrongPassword(t *testing.T) {
+	host, port := startSMTPServer(t, selfSignedCertificate(t, time.Now().Add(90*24*time.Hour)))
+
+	err := validateSMTPSpec(context.Background(), spec.Spec{
+		SMTP: &spec.SMTPSpec{
+			Name:            "mx",
+			Host:            host,
+			Port:            port,
+			StartTLS:        true,
+			InsecureSkipTLS: true,
+			Timeout:         2 * time.Second,
+			Auth:            &spec.SMTPAuth{Username: "alice", Password: "wrong"},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "auth:") {
+		t.Fatalf("validateSMTPSpec() error = %v, want auth failure", err)
+	}
+}
+
+func TestParseSMTPGreeting(t *testing.T) {
+	got := parseSMTPGreeting("220-mx.example.test ESMTP ready\r\n220 no UCE\r\n")
+	if got != "mx.example.test ESMTP ready\nno UCE" {
+		t.Fatalf("parseSMTPGreeting() = %q", got)
+	}
+}
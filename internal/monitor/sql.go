@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func validatePostgresSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	if parsedSpec.Postgres == nil {
+		return fmt.Errorf("missing postgres spec")
+	}
+	return checkSQLSpec(ctx, "pgx", "postgres", *parsedSpec.Postgres)
+}
+
+func validateMySQLSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	if parsedSpec.MySQL == nil {
+		return fmt.Errorf("missing mysql spec")
+	}
+	return checkSQLSpec(ctx, "mysql", "mysql", *parsedSpec.MySQL)
+}
+
+func checkSQLSpec(ctx context.Context, driverName, kind string, sqlSpec spec.SQLSpec) error {
+	dsn, err := resolveSecret(kind+".dsn", sqlSpec.DSN, sqlSpec.DSNEnv, sqlSpec.DSNFile)
+	if err != nil {
+		return err
+	}
+
+	timeout := sqlSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	db, err := sql.Open(driverName, strings.TrimSpace(dsn))
+	if err != nil {
+		return fmt.Errorf("open %s: %w", kind, err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(checkCtx); err != nil {
+		return fmt.Errorf("connect %s: %w", kind, err)
+	}
+	if sqlSpec.Query == nil {
+		return nil
+	}
+
+	row, err := querySQLFirstRow(checkCtx, db, sqlSpec.Query.SQL)
+	if err != nil {
+		return err
+	}
+	for _, assertion := range sqlSpec.Query.Asserts {
+		if err := evaluateProbeAssert(assertion, row); err != nil {
+			return fmt.Errorf("assert %q failed: %w", assertion.ID, err)
+		}
+	}
+	return nil
+}
+
+// querySQLFirstRow returns the first result row keyed by column name, with the
+// first column also available as "value" unless a column has that name.
+func querySQLFirstRow(ctx context.Context, db *sql.DB, query string) (map[string]any, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("query columns: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("query: %w", err)
+		}
+		return nil, fmt.Errorf("query returned no rows")
+	}
+
+	values := make([]any, len(columns))
+	targets := make([]any, len(columns))
+	for idx := range values {
+		targets[idx] = &values[idx]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return nil, fmt.Errorf("scan row: %w", err)
+	}
+
+	row := make(map[string]any, len(columns)+1)
+	if len(columns) > 0 {
+		row["value"] = sqlAssertValue(values[0])
+	}
+	for idx, column := range columns {
+		row[column] = sqlAssertValue(values[idx])
+	}
+	return row, nil
+}
+
+// sqlAssertValue converts driver values into the types probe asserts compare.
+func sqlAssertValue(value any) any {
+	switch typed := value.(type) {
+	case []byte:
+		return string(typed)
+	case time.Time:
+		return typed.UTC().Format(time.RFC3339Nano)
+	default:
+		return typed
+	}
+}
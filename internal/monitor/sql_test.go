@@ -0,0 +1,198 @@
+package monitor
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+const sqlTestDriver = "eddie-sqltest"
+
+var registerSQLTestDriver sync.Once
+
+// sqlTestResults maps a DSN to the single row the fake driver returns.
+var sqlTestResults sync.Map
+
+type sqlTestResult struct {
+	columns []string
+	values  []driver.Value
+	pingErr error
+}
+
+type sqlTestDriverImpl struct{}
+
+func (sqlTestDriverImpl) Open(dsn string) (driver.Conn, error) {
+	raw, ok := sqlTestResults.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("unknown test dsn %q", dsn)
+	}
+	return &sqlTestConn{result: raw.(sqlTestResult)}, nil
+}
+
+type sqlTestConn struct {
+	result sqlTestResult
+}
+
+func (c *sqlTestConn) Prepare(string) (driver.Stmt, error) {
+	return &sqlTestStmt{result: c.result}, nil
+}
+func (c *sqlTestConn) Close() error               { return nil }
+func (c *sqlTestConn) Begin() (driver.Tx, error)  { return nil, fmt.Errorf("not supported") }
+func (c *sqlTestConn) Ping(context.Context) error { return c.result.pingErr }
+
+type sqlTestStmt struct {
+	result sqlTestResult
+}
+
+func (s *sqlTestStmt) Close() error  { return nil }
+func (s *sqlTestStmt) NumInput() int { return -1 }
+func (s *sqlTestStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+func (s *sqlTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &sqlTestRows{result: s.result}, nil
+}
+
+type sqlTestRows struct {
+	result sqlTestResult
+	done   bool
+}
+
+func (r *sqlTestRows) Columns() []string { return r.result.columns }
+func (r *sqlTestRows) Close() error      { return nil }
+func (r *sqlTestRows) Next(dest []driver.Value) error {
+	if r.done || r.result.values == nil {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.result.values)
+	return nil
+}
+
+// useSQLTestResult registers the row the fake driver returns for this test
+// and returns the DSN that selects it.
+func useSQLTestResult(t *testing.T, result sqlTestResult) string {
+	t.Helper()
+
+	registerSQLTestDriver.Do(func() {
+		sql.Register(sqlTestDriver, sqlTestDriverImpl{})
+	})
+	dsn := t.Name()
+	sqlTestResults.Store(dsn, result)
+	t.Cleanup(func() {
+		sqlTestResults.Delete(dsn)
+	})
+	return dsn
+}
+
+func TestCheckSQLSpecReplicationLag(t *testing.T) {
+	dsn := useSQLTestResult(t, sqlTestResult{
+		columns: []string{"lag_seconds", "state"},
+		values:  []driver.Value{[]byte("4.25"), "streaming"},
+	})
+
+	err := checkSQLSpec(context.Background(), sqlTestDriver, "postgres", spec.SQLSpec{
+		Name:    "db",
+		DSN:     dsn,
+		Timeout: 2 * time.Second,
+		Query: &spec.SQLQuery{
+			SQL: "SELECT lag_seconds, state FROM replication",
+			Asserts: []spec.ProbeAssert{
+				{ID: "lag", Op: "lte", Left: spec.ProbeOperand{Ref: "value"}, Right: spec.ProbeOperand{Value: 30}},
+				{ID: "state", Op: "matches", Left: spec.ProbeOperand{Ref: "state"}, Right: spec.ProbeOperand{Value: "^stream"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("checkSQLSpec() error = %v, want nil", err)
+	}
+}
+
+func TestCheckSQLSpecReplicationLagTooHigh(t *testing.T) {
+	dsn := useSQLTestResult(t, sqlTestResult{
+		columns: []string{"lag_seconds", "state"},
+		values:  []driver.Value{[]byte("4.25"), "streaming"},
+	})
+
+	err := checkSQLSpec(context.Background(), sqlTestDriver, "postgres", spec.SQLSpec{
+		Name:    "db",
+		DSN:     dsn,
+		Timeout: 2 * time.Second,
+		Query: &spec.SQLQuery{
+			SQL: "SELECT lag_seconds, state FROM replication",
+			Asserts: []spec.ProbeAssert{
+				{ID: "lag", Op: "lte", Left: spec.ProbeOperand{Ref: "value"}, Right: spec.ProbeOperand{Value: 2}},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), `assert "lag" failed`) {
+		t.Fatalf("checkSQLSpec() error = %v, want lag assertion failure", err)
+	}
+}
+
+func TestCheckSQLSpecNoRows(t *testing.T) {
+	dsn := useSQLTestResult(t, sqlTestResult{columns: []string{"id"}})
+
+	err := checkSQLSpec(context.Background(), sqlTestDriver, "mysql", spec.SQLSpec{
+		Name:    "db",
+		DSN:     dsn,
+		Timeout: 2 * time.Second,
+		Query:   &spec.SQLQuery{SQL: "SELECT id FROM jobs WHERE failed"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no rows") {
+		t.Fatalf("checkSQLSpec() error = %v, want no rows", err)
+	}
+}
+
+func TestCheckSQLSpecConnectFailure(t *testing.T) {
+	dsn := useSQLTestResult(t, sqlTestResult{pingErr: fmt.Errorf("connection refused")})
+
+	err := checkSQLSpec(context.Background(), sqlTestDriver, "mysql", spec.SQLSpec{
+		Name:    "db",
+		DSN:     dsn,
+		Timeout: 2 * time.Second,
+	})
+	if err == nil || !strings.Contains(err.Error(), "connect mysql: connection refused") {
+		t.Fatalf("checkSQLSpec() error = %v, want connect failure", err)
+	}
+}
+
+func TestCheckSQLSpecDSNFromFile(t *testing.T) {
+	dsn := useSQLTestResult(t, sqlTestResult{columns: []string{"ok"}, values: []driver.Value{int64(1)}})
+	path := filepath.Join(t.TempDir(), "dsn")
+	if err := os.WriteFile(path, []byte(dsn+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	err := checkSQLSpec(context.Background(), sqlTestDriver, "postgres", spec.SQLSpec{
+		Name:    "db",
+		DSNFile: path,
+		Timeout: 2 * time.Second,
+		Query: &spec.SQLQuery{
+			SQL:     "SELECT 1 AS ok",
+			Asserts: []spec.ProbeAssert{{ID: "ok", Op: "eq", Left: spec.ProbeOperand{Ref: "ok"}, Right: spec.ProbeOperand{Value: 1}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("checkSQLSpec() error = %v, want nil", err)
+	}
+}
+
+func TestSQLDriversRegistered(t *testing.T) {
+	drivers := strings.Join(sql.Drivers(), ",")
+	for _, name := range []string{"pgx", "mysql"} {
+		if !strings.Contains(drivers, name) {
+			t.Fatalf("sql.Drivers() = %s, want %s", drivers, name)
+		}
+	}
+}
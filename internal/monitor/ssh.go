@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"golang.org/x/crypto/ssh"
+)
+
+func validateSSHSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	sshSpec := parsedSpec.SSH
+	if sshSpec == nil {
+		return fmt.Errorf("missing ssh spec")
+	}
+
+	client, err := dialSSH(ctx, *sshSpec)
+	if err != nil || client == nil {
+		return err
+	}
+	defer client.Close()
+
+	if command := sshSpec.Command; command != nil {
+		if err := runSSHCommand(client, *command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dialSSH connects, checks the server banner and host key and authenticates
+// when auth is configured. Without auth the handshake stops after the host key
+// check and the returned client is nil.
+func dialSSH(ctx context.Context, sshSpec spec.SSHSpec) (*ssh.Client, error) {
+	timeout := sshSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	port := sshSpec.Port
+	if port == 0 {
+		port = 22
+	}
+	address := net.JoinHostPort(strings.TrimSpace(sshSpec.Host), strconv.Itoa(port))
+
+	config := &ssh.ClientConfig{
+		User:              "eddie",
+		HostKeyAlgorithms: sshSpec.HostKeyAlgorithms,
+		Timeout:           timeout,
+	}
+	hostKeyChecked := false
+	config.HostKeyCallback = func(_ string, _ net.Addr, key ssh.PublicKey) error {
+		if sshSpec.Auth != nil && len(sshSpec.HostKeyFingerprints) == 0 && !sshSpec.InsecureIgnoreHostKey {
+			// Never hand credentials to a server whose identity is unchecked.
+			return fmt.Errorf("host key %s %s is not pinned, refusing to authenticate", key.Type(), ssh.FingerprintSHA256(key))
+		}
+		if err := checkSSHHostKey(key, sshSpec.HostKeyFingerprints); err != nil {
+			return err
+		}
+		hostKeyChecked = true
+		return nil
+	}
+	if auth := sshSpec.Auth; auth != nil {
+		config.User = strings.TrimSpace(auth.Username)
+		methods, err := sshAuthMethods(*auth)
+		if err != nil {
+			return nil, err
+		}
+		config.Auth = methods
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	rawConn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", address, err)
+	}
+	if err := rawConn.SetDeadline(deadline); err != nil {
+		_ = rawConn.Close()
+		return nil, err
+	}
+	conn := &sshVersionConn{Conn: rawConn}
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, config)
+	if err := checkSSHBanner(sshSpec.Banner, conn.serverVersion()); err != nil {
+		_ = rawConn.Close()
+		return nil, err
+	}
+	if err != nil {
+		_ = rawConn.Close()
+		if sshSpec.Auth == nil && hostKeyChecked {
+			// No credentials configured: the server rejecting the anonymous
+			// login after key exchange is the expected outcome.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ssh handshake with %s: %w", address, err)
+	}
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+func checkSSHHostKey(key ssh.PublicKey, pinned []string) error {
+	if len(pinned) == 0 {
+		return nil
+	}
+	fingerprint := ssh.FingerprintSHA256(key)
+	for _, candidate := range pinned {
+		if strings.TrimSpace(candidate) == fingerprint {
+			return nil
+		}
+	}
+	return fmt.Errorf("host key %s %s is not pinned", key.Type(), fingerprint)
+}
+
+func checkSSHBanner(expect spec.ResponseExpect, version string) error {
+	if version == "" {
+		return fmt.Errorf("no ssh server version received")
+	}
+	if err := checkResponseExpect(expect, version); err != nil {
+		return fmt.Errorf("banner: %w", err)
+	}
+	return nil
+}
+
+func sshAuthMethods(auth spec.SSHAuth) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	if keyFile := strings.TrimSpace(auth.KeyFile); keyFile != "" {
+		pemBytes, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read auth.key_file: %w", err)
+		}
+		passphrase, err := resolveSecret("auth.key_passphrase", auth.KeyPassphrase, auth.KeyPassphraseEnv, auth.KeyPassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		var signer ssh.Signer
+		if passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(pemBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse auth.key_file: %w", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	password, err := resolveSecret("auth.password", auth.Password, auth.PasswordEnv, auth.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		methods = append(methods, ssh.Password(password))
+	}
+	return methods, nil
+}
+
+func runSSHCommand(client *ssh.Client, command spec.SSHCommand) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("open session: %w", err)
+	}
+	defer session.Close()
+
+	var stdout bytes.Buffer
+	session.Stdout = &stdout
+	exitCode := 0
+	if err := session.Run(command.Run); err != nil {
+		var exitErr *ssh.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("run command: %w", err)
+		}
+		exitCode = exitErr.ExitStatus()
+	}
+
+	wantExitCode := 0
+	if command.Expect.ExitCode != nil {
+		wantExitCode = *command.Expect.ExitCode
+	}
+	if exitCode != wantExitCode {
+		return fmt.Errorf("command exited with %d, want %d: %q", exitCode, wantExitCode, truncateForError(strings.TrimSpace(stdout.String())))
+	}
+	if err := checkResponseExpect(command.Expect.Stdout, strings.TrimRight(stdout.String(), "\r\n")); err != nil {
+		return fmt.Errorf("stdout: %w", err)
+	}
+	return nil
+}
+
+// sshVersionConn records the first bytes read from the server so the
+// identification line is available even when authentication fails.
+type sshVersionConn struct {
+	net.Conn
+	mu       sync.Mutex
+	received []byte
+}
+
+func (c *sshVersionConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.mu.Lock()
+	if remaining := 1024 - len(c.received); remaining > 0 {
+		c.received = append(c.received, p[:min(n, remaining)]...)
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+// serverVersion returns the "SSH-..." identification line, without CR/LF.
+func (c *sshVersionConn) serverVersion() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, line := range strings.Split(string(c.received), "\n") {
+		if strings.HasPrefix(line, "SSH-") {
+			return strings.TrimRight(line, "\r")
+		}
+	}
+	return ""
+}
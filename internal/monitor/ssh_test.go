@@ -0,0 +1,396 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+type sshTestServer struct {
+	host        string
+	port        int
+	fingerprint string
+	keyFile     string
+	dropDir     string
+}
+
+// startSSHServer runs an SSH server that accepts user "deploy" with password
+// "secret" or the generated client key, executes "echo ok" and "false", and
+// serves the local filesystem over SFTP. dropDir holds report-1.csv (10
+// minutes old), report-2.csv (2 hours old), notes.txt and an archive
+// directory.
+func startSSHServer(t *testing.T) sshTestServer {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("host signer: %v", err)
+	}
+	clientPublic, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key: %v", err)
+	}
+	authorizedKey, err := ssh.NewPublicKey(clientPublic)
+	if err != nil {
+		t.Fatalf("client public key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatalf("marshal client key: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	writeTestFile(t, keyFile, string(pem.EncodeToMemory(block)), time.Now())
+
+	dropDir := t.TempDir()
+	now := time.Now()
+	writeTestFile(t, filepath.Join(dropDir, "report-1.csv"), "a,b\n", now.Add(-10*time.Minute))
+	writeTestFile(t, filepath.Join(dropDir, "report-2.csv"), "a,b\n", now.Add(-2*time.Hour))
+	writeTestFile(t, filepath.Join(dropDir, "notes.txt"), "todo\n", now.Add(-time.Minute))
+	if err := os.Mkdir(filepath.Join(dropDir, "archive"), 0o755); err != nil {
+		t.Fatalf("mkdir archive: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		ServerVersion: "SSH-2.0-OpenSSH_9.6 eddie-test",
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "deploy" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, io.ErrUnexpectedEOF
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == "deploy" && string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.ErrUnexpectedEOF
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		_, channels, requests, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(requests)
+		for newChannel := range channels {
+			if newChannel.ChannelType() != "session" {
+				_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+				continue
+			}
+			channel, channelRequests, err := newChannel.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSHTestSession(channel, channelRequests)
+		}
+	})
+	return sshTestServer{
+		host:        host,
+		port:        port,
+		fingerprint: ssh.FingerprintSHA256(hostSigner.PublicKey()),
+		keyFile:     keyFile,
+		dropDir:     dropDir,
+	}
+}
+
+func serveSSHTestSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for request := range requests {
+		switch request.Type {
+		case "exec":
+			command := string(request.Payload[4:])
+			_ = request.Reply(true, nil)
+			exitStatus := uint32(0)
+			switch command {
+			case "echo ok":
+				_, _ = channel.Write([]byte("ok\n"))
+			default:
+				exitStatus = 1
+			}
+			_, _ = channel.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, exitStatus))
+			return
+		case "subsystem":
+			_ = request.Reply(string(request.Payload[4:]) == "sftp", nil)
+			server, err := sftp.NewServer(channel)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+			return
+		default:
+			_ = request.Reply(false, nil)
+		}
+	}
+}
+
+func TestValidateSSHSpecBannerAndHostKey(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+	}})
+	if err != nil {
+		t.Fatalf("validateSSHSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSSHSpecPasswordAuthWithCommand(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+		Auth:                &spec.SSHAuth{Username: "deploy", Password: "secret"},
+		Command:             &spec.SSHCommand{Run: "echo ok", Expect: spec.SSHCommandExpect{Stdout: spec.ResponseExpect{Exact: "ok"}}},
+	}})
+	if err != nil {
+		t.Fatalf("validateSSHSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSSHSpecKeyAuthWithExpectedExitCode(t *testing.T) {
+	server := startSSHServer(t)
+	exitCode := 1
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+		Auth:                &spec.SSHAuth{Username: "deploy", KeyFile: server.keyFile},
+		Command:             &spec.SSHCommand{Run: "false", Expect: spec.SSHCommandExpect{ExitCode: &exitCode}},
+	}})
+	if err != nil {
+		t.Fatalf("validateSSHSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSSHSpecHostKeyChanged(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{"SHA256:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "is not pinned") {
+		t.Fatalf("validateSSHSpec() error = %v, want host key mismatch", err)
+	}
+}
+
+func TestValidateSSHSpecBannerMismatch(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Regex: "^SSH-2.0-dropbear"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "banner:") {
+		t.Fatalf("validateSSHSpec() error = %v, want banner mismatch", err)
+	}
+}
+
+func TestValidateSSHSpecWrongPassword(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+		Auth:                &spec.SSHAuth{Username: "deploy", Password: "wrong"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "unable to authenticate") {
+		t.Fatalf("validateSSHSpec() error = %v, want auth failure", err)
+	}
+}
+
+func TestValidateSSHSpecCommandFails(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+		Auth:                &spec.SSHAuth{Username: "deploy", Password: "secret"},
+		Command:             &spec.SSHCommand{Run: "false"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "command exited with 1, want 0") {
+		t.Fatalf("validateSSHSpec() error = %v, want exit code failure", err)
+	}
+}
+
+func TestValidateSSHSpecStdoutMismatch(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                "bastion",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Banner:              spec.ResponseExpect{Contains: "OpenSSH"},
+		Auth:                &spec.SSHAuth{Username: "deploy", Password: "secret"},
+		Command:             &spec.SSHCommand{Run: "echo ok", Expect: spec.SSHCommandExpect{Stdout: spec.ResponseExpect{Contains: "active"}}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "stdout:") {
+		t.Fatalf("validateSSHSpec() error = %v, want stdout mismatch", err)
+	}
+}
+
+func TestValidateSFTPSpecCountsMatchingFiles(t *testing.T) {
+	server := startSSHServer(t)
+	two := 2
+
+	err := validateSFTPSpec(context.Background(), spec.Spec{SFTP: &spec.SSHSpec{
+		Name:                "drop",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Auth:                &spec.SSHAuth{Username: "deploy", KeyFile: server.keyFile},
+		List: &spec.SFTPList{
+			Path:    server.dropDir,
+			Pattern: "*.csv",
+			Expect:  spec.SFTPListExpect{CountEQ: &two, MaxAge: 30 * time.Minute},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("validateSFTPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateSFTPSpecFailsOnTooFewFiles(t *testing.T) {
+	server := startSSHServer(t)
+	three := 3
+
+	err := validateSFTPSpec(context.Background(), spec.Spec{SFTP: &spec.SSHSpec{
+		Name:                "drop",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Auth:                &spec.SSHAuth{Username: "deploy", KeyFile: server.keyFile},
+		List: &spec.SFTPList{
+			Path:    server.dropDir,
+			Pattern: "*.csv",
+			Expect:  spec.SFTPListExpect{CountGTE: &three},
+		},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "unexpected file count: got 2, want >= 3") {
+		t.Fatalf("validateSFTPSpec() error = %v, want file count failure", err)
+	}
+}
+
+func TestValidateSFTPSpecFailsOnStaleFiles(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSFTPSpec(context.Background(), spec.Spec{SFTP: &spec.SSHSpec{
+		Name:                "drop",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Auth:                &spec.SSHAuth{Username: "deploy", KeyFile: server.keyFile},
+		List: &spec.SFTPList{
+			Path:    server.dropDir,
+			Pattern: "*.csv",
+			Expect:  spec.SFTPListExpect{MaxAge: 5 * time.Minute},
+		},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "newest file is") {
+		t.Fatalf("validateSFTPSpec() error = %v, want max_age failure", err)
+	}
+}
+
+func TestValidateSFTPSpecFailsOnMissingDirectory(t *testing.T) {
+	server := startSSHServer(t)
+	missing := filepath.Join(server.dropDir, "missing")
+
+	err := validateSFTPSpec(context.Background(), spec.Spec{SFTP: &spec.SSHSpec{
+		Name:                "drop",
+		Host:                server.host,
+		Port:                server.port,
+		Timeout:             2 * time.Second,
+		HostKeyFingerprints: []string{server.fingerprint},
+		Auth:                &spec.SSHAuth{Username: "deploy", KeyFile: server.keyFile},
+		List:                &spec.SFTPList{Path: missing},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "list "+missing+": file does not exist") {
+		t.Fatalf("validateSFTPSpec() error = %v, want missing directory", err)
+	}
+}
+
+func TestValidateSSHSpecRefusesAuthWithoutPinnedHostKey(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:    "bastion",
+		Host:    server.host,
+		Port:    server.port,
+		Timeout: 2 * time.Second,
+		Auth:    &spec.SSHAuth{Username: "deploy", Password: "secret"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "is not pinned, refusing to authenticate") {
+		t.Fatalf("validateSSHSpec() error = %v, want refusal", err)
+	}
+}
+
+func TestValidateSSHSpecInsecureIgnoreHostKey(t *testing.T) {
+	server := startSSHServer(t)
+
+	err := validateSSHSpec(context.Background(), spec.Spec{SSH: &spec.SSHSpec{
+		Name:                  "bastion",
+		Host:                  server.host,
+		Port:                  server.port,
+		Timeout:               2 * time.Second,
+		InsecureIgnoreHostKey: true,
+		Auth:                  &spec.SSHAuth{Username: "deploy", Password: "secret"},
+		Command:               &spec.SSHCommand{Run: "echo ok", Expect: spec.SSHCommandExpect{Stdout: spec.ResponseExpect{Exact: "ok"}}},
+	}})
+	if err != nil {
+		t.Fatalf("validateSSHSpec() error = %v, want nil", err)
+	}
+}
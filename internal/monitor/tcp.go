@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+const tcpMaxResponseBytes = 64 * 1024
+
+func validateTCPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	tcpSpec := parsedSpec.TCP
+	if tcpSpec == nil {
+		return fmt.Errorf("missing tcp spec")
+	}
+
+	host := strings.TrimSpace(tcpSpec.Host)
+	if host == "" {
+		return fmt.Errorf("tcp.host is required")
+	}
+	if tcpSpec.Port <= 0 || tcpSpec.Port > 65535 {
+		return fmt.Errorf("tcp.port must be between 1 and 65535")
+	}
+
+	timeout := tcpSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(host, strconv.Itoa(tcpSpec.Port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(dialCtx, "tcp", addr)
+	if tcpSpec.Expect.Closed {
+		if err == nil {
+			_ = conn.Close()
+			return fmt.Errorf("port %s is open, want closed", addr)
+		}
+		if isClosedPortError(err) {
+			return nil
+		}
+		return fmt.Errorf("tcp connect: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("tcp connect: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := dialCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if tcpSpec.Send != "" {
+		if _, err := io.WriteString(conn, tcpSpec.Send); err != nil {
+			return fmt.Errorf("tcp send: %w", err)
+		}
+	}
+
+	expect := tcpSpec.Expect.Response
+	if expect.IsZero() {
+		return nil
+	}
+
+	response, readErr := readTCPResponse(conn, expect)
+	if err := checkResponseExpect(expect, response); err != nil {
+		if readErr != nil {
+			return fmt.Errorf("%w (read: %v)", err, readErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// readTCPResponse reads until the expectation matches, the peer closes the
+// connection, the size limit is reached, or the deadline expires.
+func readTCPResponse(conn net.Conn, expect spec.ResponseExpect) (string, error) {
+	buffer := make([]byte, 4096)
+	var response []byte
+	for len(response) < tcpMaxResponseBytes {
+		n, err := conn.Read(buffer)
+		response = append(response, buffer[:n]...)
+		if n > 0 && checkResponseExpect(expect, string(response)) == nil {
+			return string(response), nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return string(response), nil
+			}
+			return string(response), err
+		}
+	}
+	return string(response), nil
+}
+
+func isClosedPortError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func TestValidateTCPSpecBannerContains(t *testing.T) {
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("220 mail.example.test ESMTP ready\r\n"))
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	err := validateTCPSpec(context.Background(), spec.Spec{
+		TCP: &spec.TCPSpec{
+			Name:    "banner",
+			Host:    host,
+			Port:    port,
+			Timeout: 2 * time.Second,
+			Expect: spec.TCPExpect{
+				Response: spec.ResponseExpect{Contains: "ESMTP"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateTCPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateTCPSpecSendAndRegex(t *testing.T) {
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.TrimSpace(line) == "PING" {
+			_, _ = conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+
+	err := validateTCPSpec(context.Background(), spec.Spec{
+		TCP: &spec.TCPSpec{
+			Name:    "ping",
+			Host:    host,
+			Port:    port,
+			Timeout: 2 * time.Second,
+			Send:    "PING\r\n",
+			Expect: spec.TCPExpect{
+				Response: spec.ResponseExpect{Regex: `^\+PONG\r\n$`},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateTCPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateTCPSpecResponseMismatch(t *testing.T) {
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("-ERR unknown\r\n"))
+	})
+
+	err := validateTCPSpec(context.Background(), spec.Spec{
+		TCP: &spec.TCPSpec{
+			Name:    "mismatch",
+			Host:    host,
+			Port:    port,
+			Timeout: 2 * time.Second,
+			Expect: spec.TCPExpect{
+				Response: spec.ResponseExpect{Exact: "+PONG\r\n"},
+			},
+		},
+	})
+	if err == nil {
+		t.Fatalf("validateTCPSpec() error = nil, want mismatch")
+	}
+	if !strings.Contains(err.Error(), "unexpected response") {
+		t.Fatalf("validateTCPSpec() error = %v, want unexpected response", err)
+	}
+}
+
+func TestValidateTCPSpecExpectClosed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	host, port := splitHostPortForTest(t, listener.Addr().String())
+	_ = listener.Close()
+
+	closedSpec := spec.Spec{
+		TCP: &spec.TCPSpec{
+			Name:    "closed",
+			Host:    host,
+			Port:    port,
+			Timeout: 2 * time.Second,
+			Expect:  spec.TCPExpect{Closed: true},
+		},
+	}
+	if err := validateTCPSpec(context.Background(), closedSpec); err != nil {
+		t.Fatalf("validateTCPSpec() closed port error = %v, want nil", err)
+	}
+
+	openHost, openPort := startTCPServer(t, func(net.Conn) {})
+	closedSpec.TCP.Host = openHost
+	closedSpec.TCP.Port = openPort
+	err = validateTCPSpec(context.Background(), closedSpec)
+	if err == nil {
+		t.Fatalf("validateTCPSpec() open port error = nil, want failure")
+	}
+	if !strings.Contains(err.Error(), "want closed") {
+		t.Fatalf("validateTCPSpec() error = %v, want closed message", err)
+	}
+}
+
+func startTCPServer(t *testing.T, handle func(net.Conn)) (string, int) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				handle(c)
+			}(conn)
+		}
+	}()
+
+	return splitHostPortForTest(t, listener.Addr().String())
+}
+
+func splitHostPortForTest(t *testing.T, addr string) (string, int) {
+	t.Helper()
+
+	host, portRaw, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("SplitHostPort() error = %v", err)
+	}
+	port, err := strconv.Atoi(portRaw)
+	if err != nil {
+		t.Fatalf("Atoi() error = %v", err)
+	}
+	return host, port
+}
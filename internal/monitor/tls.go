@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
@@ -50,7 +51,13 @@ func validateTLSSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		serverName = host
 	}
 
-	minVersion, err := parseTLSVersion(tlsSpec.MinVersion)
+	config, err := buildClientTLSConfig(spec.ClientTLS{
+		CAFile:     tlsSpec.CAFile,
+		ClientCert: tlsSpec.ClientCert,
+		ClientKey:  tlsSpec.ClientKey,
+		ServerName: serverName,
+		MinVersion: tlsSpec.MinVersion,
+	}, !verify)
 	if err != nil {
 		return err
 	}
@@ -58,11 +65,6 @@ func validateTLSSpec(ctx context.Context, parsedSpec spec.Spec) error {
 	addr := net.JoinHostPort(host, strconv.Itoa(port))
 
 	dialer := &net.Dialer{Timeout: timeout}
-	config := &tls.Config{
-		InsecureSkipVerify: !verify,
-		ServerName:         serverName,
-		MinVersion:         minVersion,
-	}
 	tlsDialer := &tls.Dialer{
 		NetDialer: dialer,
 		Config:    config,
@@ -70,7 +72,7 @@ func validateTLSSpec(ctx context.Context, parsedSpec spec.Spec) error {
 
 	conn, err := tlsDialer.DialContext(ctx, "tcp", addr)
 	if err != nil {
-		return fmt.Errorf("tls connect: %w", err)
+		return fmt.Errorf("tls connect: %w", describeTLSError(err))
 	}
 	defer conn.Close()
 
@@ -82,6 +84,11 @@ func validateTLSSpec(ctx context.Context, parsedSpec spec.Spec) error {
 	_ = conn.SetDeadline(time.Now().Add(timeout))
 
 	state := tlsConn.ConnectionState()
+	if len(config.Certificates) > 0 && state.Version == tls.VersionTLS13 {
+		if err := awaitClientCertificateVerdict(tlsConn, timeout); err != nil {
+			return fmt.Errorf("tls connect: %w", describeTLSError(err))
+		}
+	}
 	if len(state.PeerCertificates) == 0 {
 		return fmt.Errorf("no peer certificates presented")
 	}
@@ -96,15 +103,43 @@ func validateTLSSpec(ctx context.Context, parsedSpec spec.Spec) error {
 		if days < 0 {
 			return fmt.Errorf("tls.cert_min_days_valid must be >= 0")
 		}
-		cutoff := time.Now().Add(time.Duration(days) * 24 * time.Hour)
-		if !leaf.NotAfter.After(cutoff) {
-			return fmt.Errorf("certificate expires too soon: not_after=%s", leaf.NotAfter.UTC().Format(time.RFC3339))
+		if err := checkCertMinDaysValid(leaf, days); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// clientCertificateAlertWait bounds how long a TLS 1.3 check waits for the
+// server to reject the client certificate after the handshake.
+const clientCertificateAlertWait = time.Second
+
+// awaitClientCertificateVerdict reads briefly from a TLS 1.3 connection. The
+// client finishes its handshake before the server has checked the client
+// certificate, so a rejection only arrives as an alert on the next read. A
+// timeout, EOF or application data mean the certificate was accepted.
+func awaitClientCertificateVerdict(conn *tls.Conn, timeout time.Duration) error {
+	wait := min(clientCertificateAlertWait, timeout)
+	if err := conn.SetReadDeadline(time.Now().Add(wait)); err != nil {
+		return err
+	}
+	_, err := conn.Read(make([]byte, 1))
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "remote error" {
+		return err
+	}
+	return nil
+}
+
+func checkCertMinDaysValid(cert *x509.Certificate, days int) error {
+	cutoff := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	if !cert.NotAfter.After(cutoff) {
+		return fmt.Errorf("certificate expires too soon: not_after=%s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
 func isSelfSigned(cert *x509.Certificate) bool {
 	if cert == nil {
 		return false
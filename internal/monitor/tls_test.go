@@ -10,6 +10,7 @@ import (
 	"encoding/pem"
 	"math/big"
 	"net"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -227,3 +228,172 @@ func selfSignedCertificate(t *testing.T, notAfter time.Time) tls.Certificate {
 	}
 	return certificate
 }
+
+// testPKI is a private CA with one server certificate for 127.0.0.1 and one
+// client certificate, written to PEM files for the spec options.
+type testPKI struct {
+	CAFile         string
+	Pool           *x509.CertPool
+	Server         tls.Certificate
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+
+	dir := t.TempDir()
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "eddie test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	issue := func(serial int64, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("GenerateKey() error = %v", err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: commonName},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("CreateCertificate() error = %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	}
+
+	serverCert, serverKey := issue(2, "127.0.0.1", x509.ExtKeyUsageServerAuth)
+	server, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("X509KeyPair() error = %v", err)
+	}
+	clientCert, clientKey := issue(3, "eddie", x509.ExtKeyUsageClientAuth)
+
+	pki := testPKI{
+		CAFile:         filepath.Join(dir, "ca.pem"),
+		Pool:           x509.NewCertPool(),
+		Server:         server,
+		ClientCertFile: filepath.Join(dir, "client.pem"),
+		ClientKeyFile:  filepath.Join(dir, "client-key.pem"),
+	}
+	pki.Pool.AddCert(caCert)
+	writeTestFile(t, pki.CAFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})), time.Now())
+	writeTestFile(t, pki.ClientCertFile, string(clientCert), time.Now())
+	writeTestFile(t, pki.ClientKeyFile, string(clientKey), time.Now())
+	return pki
+}
+
+func TestValidateTLSSpecWithCAFileAndClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		tlsConn := tls.Server(conn, &tls.Config{
+			Certificates: []tls.Certificate{pki.Server},
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+			ClientCAs:    pki.Pool,
+		})
+		_ = tlsConn.Handshake()
+	})
+
+	tests := []struct {
+		name    string
+		mutate  func(*spec.TLSSpec)
+		wantErr string
+	}{
+		{name: "private ca", mutate: func(s *spec.TLSSpec) { s.CAFile = pki.CAFile }},
+		{
+			name: "private ca with client certificate",
+			mutate: func(s *spec.TLSSpec) {
+				s.CAFile = pki.CAFile
+				s.ClientCert = pki.ClientCertFile
+				s.ClientKey = pki.ClientKeyFile
+			},
+		},
+		{name: "system roots", mutate: func(*spec.TLSSpec) {}, wantErr: "server certificate untrusted"},
+		{
+			name: "unreadable client key",
+			mutate: func(s *spec.TLSSpec) {
+				s.CAFile = pki.CAFile
+				s.ClientCert = pki.ClientCertFile
+				s.ClientKey = pki.CAFile
+			},
+			wantErr: "bad client certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsSpec := &spec.TLSSpec{Name: "private", Host: host, Port: port, Timeout: 2 * time.Second}
+			tt.mutate(tlsSpec)
+			err := validateTLSSpec(context.Background(), spec.Spec{TLS: tlsSpec})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("validateTLSSpec() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validateTLSSpec() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTLSSpecDetectsRejectedClientCertificateOnTLS13(t *testing.T) {
+	pki := newTestPKI(t)
+	otherPKI := newTestPKI(t)
+	host, port := startTCPServer(t, func(conn net.Conn) {
+		tlsConn := tls.Server(conn, &tls.Config{
+			Certificates: []tls.Certificate{pki.Server},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    pki.Pool,
+			MinVersion:   tls.VersionTLS13,
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		// Stay silent like an HTTPS server waiting for a request.
+		_, _ = tlsConn.Read(make([]byte, 1))
+	})
+
+	accepted := &spec.TLSSpec{
+		Name:       "mtls",
+		Host:       host,
+		Port:       port,
+		Timeout:    2 * time.Second,
+		CAFile:     pki.CAFile,
+		ClientCert: pki.ClientCertFile,
+		ClientKey:  pki.ClientKeyFile,
+	}
+	if err := validateTLSSpec(context.Background(), spec.Spec{TLS: accepted}); err != nil {
+		t.Fatalf("validateTLSSpec() error = %v, want nil", err)
+	}
+
+	rejected := *accepted
+	rejected.ClientCert = otherPKI.ClientCertFile
+	rejected.ClientKey = otherPKI.ClientKeyFile
+	err := validateTLSSpec(context.Background(), spec.Spec{TLS: &rejected})
+	if err == nil || !strings.Contains(err.Error(), "client certificate rejected by server") {
+		t.Fatalf("validateTLSSpec() error = %v, want client certificate rejected", err)
+	}
+}
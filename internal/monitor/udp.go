@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+const udpMaxDatagramBytes = 64 * 1024
+
+func validateUDPSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	udpSpec := parsedSpec.UDP
+	if udpSpec == nil {
+		return fmt.Errorf("missing udp spec")
+	}
+
+	payload, err := decodeUDPPayload(udpSpec.Send)
+	if err != nil {
+		return err
+	}
+	var wantPrefix []byte
+	if udpSpec.Expect.HexPrefix != "" {
+		if wantPrefix, err = spec.DecodeHex(udpSpec.Expect.HexPrefix); err != nil {
+			return fmt.Errorf("decode udp.expect.hex_prefix: %w", err)
+		}
+	}
+
+	timeout := udpSpec.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	addr := net.JoinHostPort(strings.TrimSpace(udpSpec.Host), strconv.Itoa(udpSpec.Port))
+
+	attempts := udpSpec.Retries + 1
+	var reply []byte
+	for attempt := 1; attempt <= attempts; attempt++ {
+		reply, err = exchangeUDP(ctx, addr, payload, timeout)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("no reply from %s after %d attempt(s): %w", addr, attempts, err)
+	}
+
+	if wantPrefix != nil && !bytes.HasPrefix(reply, wantPrefix) {
+		return fmt.Errorf("reply %s does not start with %s", truncateForError(hex.EncodeToString(reply)), hex.EncodeToString(wantPrefix))
+	}
+	return checkResponseExpect(udpSpec.Expect.Response, string(reply))
+}
+
+// exchangeUDP sends one datagram and waits for the first reply.
+func exchangeUDP(ctx context.Context, addr string, payload []byte, timeout time.Duration) ([]byte, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(attemptCtx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := attemptCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(payload); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, udpMaxDatagramBytes)
+	n, err := conn.Read(buf)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("timeout after %s", timeout)
+		}
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func decodeUDPPayload(payload spec.UDPPayload) ([]byte, error) {
+	switch {
+	case payload.Hex != "":
+		decoded, err := spec.DecodeHex(payload.Hex)
+		if err != nil {
+			return nil, fmt.Errorf("decode udp.send.hex: %w", err)
+		}
+		return decoded, nil
+	case payload.Base64 != "":
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload.Base64))
+		if err != nil {
+			return nil, fmt.Errorf("decode udp.send.base64: %w", err)
+		}
+		return decoded, nil
+	default:
+		return []byte(payload.Text), nil
+	}
+}
@@ -0,0 +1,123 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+// startUDPServer answers datagrams with reply(request); it drops the first
+// drop requests to simulate packet loss.
+func startUDPServer(t *testing.T, drop int32, reply func([]byte) []byte) (string, int) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	var received atomic.Int32
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if received.Add(1) <= drop {
+				continue
+			}
+			_, _ = conn.WriteTo(reply(buf[:n]), addr)
+		}
+	}()
+	address := conn.LocalAddr().(*net.UDPAddr)
+	return address.IP.String(), address.Port
+}
+
+// a2sInfoReply mimics a Source engine A2S_INFO response header.
+func a2sInfoReply(request []byte) []byte {
+	if !bytes.HasPrefix(request, []byte("\xff\xff\xff\xffTSource Engine Query")) {
+		return []byte("unknown")
+	}
+	return []byte("\xff\xff\xff\xffI\x11eddie test server\x00")
+}
+
+// udpEchoReply prefixes the request with "echo:".
+func udpEchoReply(request []byte) []byte {
+	return append([]byte("echo:"), request...)
+}
+
+func TestValidateUDPSpec(t *testing.T) {
+	host, port := startUDPServer(t, 2, a2sInfoReply)
+
+	parsedSpec := spec.Spec{UDP: &spec.UDPSpec{
+		Name:    "game",
+		Host:    host,
+		Port:    port,
+		Timeout: 200 * time.Millisecond,
+		Retries: 2,
+		Send:    spec.UDPPayload{Hex: "ff ff ff ff 54 53 6f 75 72 63 65 20 45 6e 67 69 6e 65 20 51 75 65 72 79 00"},
+		Expect: spec.UDPExpect{
+			HexPrefix: "FF:FF:FF:FF:49",
+			Response:  spec.ResponseExpect{Contains: "eddie test server"},
+		},
+	}}
+	if err := validateUDPSpec(context.Background(), parsedSpec); err != nil {
+		t.Fatalf("validateUDPSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateUDPSpecLostBeyondRetries(t *testing.T) {
+	host, port := startUDPServer(t, 3, udpEchoReply)
+
+	err := validateUDPSpec(context.Background(), spec.Spec{UDP: &spec.UDPSpec{
+		Name:    "echo",
+		Host:    host,
+		Port:    port,
+		Timeout: 100 * time.Millisecond,
+		Retries: 2,
+		Send:    spec.UDPPayload{Text: "ping"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempt(s)") {
+		t.Fatalf("validateUDPSpec() error = %v, want retries exhausted", err)
+	}
+}
+
+func TestValidateUDPSpecRegexMismatch(t *testing.T) {
+	host, port := startUDPServer(t, 0, udpEchoReply)
+
+	err := validateUDPSpec(context.Background(), spec.Spec{UDP: &spec.UDPSpec{
+		Name:    "echo",
+		Host:    host,
+		Port:    port,
+		Timeout: 100 * time.Millisecond,
+		Send:    spec.UDPPayload{Base64: "cGluZw=="},
+		Expect:  spec.UDPExpect{Response: spec.ResponseExpect{Regex: "^pong$"}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("validateUDPSpec() error = %v, want regex mismatch", err)
+	}
+}
+
+func TestValidateUDPSpecHexPrefixMismatch(t *testing.T) {
+	host, port := startUDPServer(t, 0, udpEchoReply)
+
+	err := validateUDPSpec(context.Background(), spec.Spec{UDP: &spec.UDPSpec{
+		Name:    "echo",
+		Host:    host,
+		Port:    port,
+		Timeout: 100 * time.Millisecond,
+		Send:    spec.UDPPayload{Text: "ping"},
+		Expect:  spec.UDPExpect{HexPrefix: "ffff"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "does not start with ffff") {
+		t.Fatalf("validateUDPSpec() error = %v, want hex prefix mismatch", err)
+	}
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+const websocketReadLimit = 1 << 20
+
+func validateWebSocketSpec(ctx context.Context, parsedSpec spec.Spec) error {
+	wsSpec := parsedSpec.WebSocket
+	if wsSpec == nil {
+		return fmt.Errorf("missing websocket spec")
+	}
+
+	timeout := wsSpec.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+
+	header := make(http.Header, len(wsSpec.Headers))
+	host := ""
+	for headerName, value := range wsSpec.Headers {
+		if strings.EqualFold(headerName, "host") {
+			host = value
+			continue
+		}
+		header.Set(headerName, value)
+	}
+
+	proxyFunc, err := httpProxyFunc(ctx, wsSpec.Proxy)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc
+	if wsSpec.InsecureSkipTLS {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{Timeout: timeout, Transport: transport}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, resp, err := websocket.Dial(dialCtx, strings.TrimSpace(wsSpec.URL), &websocket.DialOptions{
+		HTTPClient:   client,
+		HTTPHeader:   header,
+		Host:         host,
+		Subprotocols: wsSpec.Subprotocols,
+	})
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("websocket handshake: unexpected status code %d: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("websocket handshake: %w", err)
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(websocketReadLimit)
+
+	if len(wsSpec.Subprotocols) > 0 && conn.Subprotocol() == "" {
+		return fmt.Errorf("server did not accept any subprotocol of %v", wsSpec.Subprotocols)
+	}
+
+	for idx, step := range wsSpec.Steps {
+		stepTimeout := step.Timeout
+		if stepTimeout <= 0 {
+			stepTimeout = timeout
+		}
+		if err := runWebSocketStep(ctx, conn, step, stepTimeout); err != nil {
+			return fmt.Errorf("step %d (%s): %w", idx+1, websocketStepAction(step), err)
+		}
+	}
+
+	if err := conn.Close(websocket.StatusNormalClosure, ""); err != nil && !isCleanWebSocketClose(err) {
+		return fmt.Errorf("close: %w", err)
+	}
+	return nil
+}
+
+func runWebSocketStep(ctx context.Context, conn *websocket.Conn, step spec.WebSocketStep, timeout time.Duration) error {
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case step.SendText != nil:
+		return conn.Write(stepCtx, websocket.MessageText, []byte(*step.SendText))
+	case step.SendJSON != nil:
+		payload, err := json.Marshal(step.SendJSON)
+		if err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+		return conn.Write(stepCtx, websocket.MessageText, payload)
+	case step.Expect != nil:
+		return expectWebSocketMessage(stepCtx, conn, *step.Expect, timeout)
+	default:
+		return fmt.Errorf("step defines no action")
+	}
+}
+
+// expectWebSocketMessage reads the next message and checks it. With
+// skip_unmatched, non-matching messages are discarded until one matches or
+// the step times out.
+func expectWebSocketMessage(ctx context.Context, conn *websocket.Conn, expect spec.WebSocketExpect, timeout time.Duration) error {
+	var lastMismatch error
+	for {
+		_, payload, err := conn.Read(ctx)
+		if err != nil {
+			if lastMismatch != nil && ctx.Err() != nil {
+				return fmt.Errorf("no matching message within %s; last mismatch: %w", timeout, lastMismatch)
+			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("no message within %s", timeout)
+			}
+			return fmt.Errorf("read: %w", err)
+		}
+
+		message := string(payload)
+		matchErr := checkResponseExpect(expect.Message, message)
+		if matchErr == nil {
+			if err := checkJSONPathExpect(message, expect.JSONPath); err != nil {
+				matchErr = fmt.Errorf("message %q: %w", truncateForError(message), err)
+			}
+		}
+		if matchErr == nil {
+			return nil
+		}
+		if !expect.SkipUnmatched {
+			return matchErr
+		}
+		lastMismatch = matchErr
+	}
+}
+
+func websocketStepAction(step spec.WebSocketStep) string {
+	switch {
+	case step.SendText != nil:
+		return "send_text"
+	case step.SendJSON != nil:
+		return "send_json"
+	case step.Expect != nil:
+		return "expect"
+	default:
+		return "unknown"
+	}
+}
+
+func isCleanWebSocketClose(err error) bool {
+	return websocket.CloseStatus(err) == websocket.StatusNormalClosure || errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/fabiant7t/eddie/internal/spec"
+)
+
+func startWebSocketServer(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"chat.v1"}})
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := r.Context()
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type":"heartbeat"}`))
+		_ = conn.Write(ctx, websocket.MessageText, []byte(`{"type":"welcome","version":2}`))
+		for {
+			messageType, payload, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			if err := conn.Write(ctx, messageType, append([]byte("echo:"), payload...)); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestValidateWebSocketSpecConversation(t *testing.T) {
+	url := startWebSocketServer(t)
+	hello := "hello"
+
+	err := validateWebSocketSpec(context.Background(), spec.Spec{
+		WebSocket: &spec.WebSocketSpec{
+			Name:         "realtime",
+			URL:          url,
+			Headers:      map[string]string{"X-Token": "secret"},
+			Subprotocols: []string{"chat.v1"},
+			Timeout:      2 * time.Second,
+			Steps: []spec.WebSocketStep{
+				{Expect: &spec.WebSocketExpect{
+					JSONPath:      map[string]any{"type": "welcome", "version": 2},
+					SkipUnmatched: true,
+				}},
+				{SendText: &hello},
+				{Expect: &spec.WebSocketExpect{Message: spec.ResponseExpect{Exact: "echo:hello"}}},
+				{SendJSON: map[string]any{"op": "ping"}},
+				{Expect: &spec.WebSocketExpect{Message: spec.ResponseExpect{Regex: `^echo:\{"op":"ping"\}$`}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateWebSocketSpec() error = %v, want nil", err)
+	}
+}
+
+func TestValidateWebSocketSpecReportsFailingStep(t *testing.T) {
+	url := startWebSocketServer(t)
+	hello := "hello"
+
+	err := validateWebSocketSpec(context.Background(), spec.Spec{
+		WebSocket: &spec.WebSocketSpec{
+			Name:         "realtime",
+			URL:          url,
+			Headers:      map[string]string{"X-Token": "secret"},
+			Subprotocols: []string{"chat.v1"},
+			Timeout:      2 * time.Second,
+			Steps: []spec.WebSocketStep{
+				{SendText: &hello},
+				{Expect: &spec.WebSocketExpect{Message: spec.ResponseExpect{Contains: "welcome"}}},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "step 2 (expect)") || !strings.Contains(err.Error(), "heartbeat") {
+		t.Fatalf("validateWebSocketSpec() error = %v, want step 2 failure", err)
+	}
+}
+
+func TestValidateWebSocketSpecExpectTimeout(t *testing.T) {
+	url := startWebSocketServer(t)
+
+	err := validateWebSocketSpec(context.Background(), spec.Spec{
+		WebSocket: &spec.WebSocketSpec{
+			Name:         "realtime",
+			URL:          url,
+			Headers:      map[string]string{"X-Token": "secret"},
+			Subprotocols: []string{"chat.v1"},
+			Timeout:      2 * time.Second,
+			Steps: []spec.WebSocketStep{
+				{
+					Expect:  &spec.WebSocketExpect{Message: spec.ResponseExpect{Contains: "never"}, SkipUnmatched: true},
+					Timeout: 200 * time.Millisecond,
+				},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "step 1 (expect): no matching message") {
+		t.Fatalf("validateWebSocketSpec() error = %v, want timeout", err)
+	}
+}
+
+func TestValidateWebSocketSpecHandshakeFailure(t *testing.T) {
+	url := startWebSocketServer(t)
+
+	err := validateWebSocketSpec(context.Background(), spec.Spec{
+		WebSocket: &spec.WebSocketSpec{
+			Name:         "realtime",
+			URL:          url,
+			Subprotocols: []string{"chat.v1"},
+			Timeout:      2 * time.Second,
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unexpected status code 401") {
+		t.Fatalf("validateWebSocketSpec() error = %v, want handshake failure", err)
+	}
+}
+
+func TestValidateWebSocketSpecThroughSOCKS5Proxy(t *testing.T) {
+	url := startWebSocketServer(t)
+	socksProxy, proxied := startSOCKS5Proxy(t)
+	hello := "hello"
+
+	err := validateWebSocketSpec(context.Background(), spec.Spec{
+		WebSocket: &spec.WebSocketSpec{
+			Name:         "realtime",
+			URL:          url,
+			Headers:      map[string]string{"X-Token": "secret"},
+			Subprotocols: []string{"chat.v1"},
+			Proxy:        "socks5://eddie:s3cret@" + socksProxy,
+			Timeout:      2 * time.Second,
+			Steps: []spec.WebSocketStep{
+				{SendText: &hello},
+				{Expect: &spec.WebSocketExpect{Message: spec.ResponseExpect{Exact: "echo:hello"}, SkipUnmatched: true}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateWebSocketSpec() error = %v, want nil", err)
+	}
+	if got := proxied.Load(); got != 1 {
+		t.Fatalf("proxied connections = %d, want 1", got)
+	}
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"sync"
+	"time"
+)
+
+// HeartbeatSignal is one kind of inbound heartbeat check-in.
+type HeartbeatSignal string
+
+const (
+	HeartbeatSuccess HeartbeatSignal = "success"
+	HeartbeatStart   HeartbeatSignal = "start"
+	HeartbeatFailure HeartbeatSignal = "fail"
+)
+
+// HeartbeatState tracks the latest check-ins for one heartbeat spec.
+type HeartbeatState struct {
+	LastStartAt   time.Time
+	LastSuccessAt time.Time
+	LastFailureAt time.Time
+}
+
+// HeartbeatStore keeps heartbeat check-ins in memory.
+type HeartbeatStore struct {
+	mu     sync.RWMutex
+	states map[string]HeartbeatState
+}
+
+// NewHeartbeatStore creates an in-memory heartbeat store.
+func NewHeartbeatStore() *HeartbeatStore {
+	return &HeartbeatStore{
+		states: make(map[string]HeartbeatState),
+	}
+}
+
+// Get returns heartbeat state for spec name, if any.
+func (s *HeartbeatStore) Get(specName string) (HeartbeatState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	heartbeatState, ok := s.states[specName]
+	return heartbeatState, ok
+}
+
+// Record stores one check-in signal for spec name.
+func (s *HeartbeatStore) Record(specName string, signal HeartbeatSignal, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	heartbeatState := s.states[specName]
+	switch signal {
+	case HeartbeatStart:
+		heartbeatState.LastStartAt = at
+	case HeartbeatFailure:
+		heartbeatState.LastFailureAt = at
+	default:
+		heartbeatState.LastSuccessAt = at
+	}
+	s.states[specName] = heartbeatState
+}
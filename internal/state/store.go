@@ -1,6 +1,8 @@
 package state
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -20,6 +22,42 @@ type SpecState struct {
 	ConsecutiveSuccesses int
 	LastCycleStartedAt   time.Time
 	LastCycleAt          time.Time
+	LastError            string
+	Timings              []RequestTiming
+}
+
+// RequestTiming is the phase breakdown of one HTTP request made by a check.
+// Phases that did not happen (for example TLS on plain HTTP) stay zero.
+type RequestTiming struct {
+	Label   string
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+}
+
+// String formats the timing as "label: dns 3ms, connect 1ms, ..., total 9ms".
+func (t RequestTiming) String() string {
+	parts := make([]string, 0, 5)
+	for _, phase := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"dns", t.DNS},
+		{"connect", t.Connect},
+		{"tls", t.TLS},
+		{"ttfb", t.TTFB},
+	} {
+		if phase.value > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", phase.name, phase.value.Round(time.Millisecond)))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("total %s", t.Total.Round(time.Millisecond)))
+	if t.Label == "" {
+		return strings.Join(parts, ", ")
+	}
+	return t.Label + ": " + strings.Join(parts, ", ")
 }
 
 // Store defines state persistence behavior.